@@ -0,0 +1,184 @@
+package gogu
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+)
+
+// Backoff defines the policy used for computing the delay between the retries.
+// By default the delay grows exponentially, starting from the initial interval.
+type Backoff struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between two retries. Zero means that the delay is not capped.
+	MaxInterval time.Duration
+	// Multiplier is the factor by which the delay grows after each retry. It defaults to 2.
+	Multiplier float64
+	// Jitter enables the decorrelated jitter, in which case the delay is a random value
+	// between the initial interval and three times the previous delay, instead of growing exponentially.
+	Jitter bool
+	// MaxElapsedTime stops the retries once the time elapsed since the first attempt
+	// would exceed it. Zero means that the elapsed time is not limited.
+	MaxElapsedTime time.Duration
+	// OnRetry, if set, is invoked before each retry with the number of the failed attempts,
+	// the error of the last attempt and the delay until the next one.
+	OnRetry func(attempt int, err error, delay time.Duration)
+	// Clock is the time source used for measuring the elapsed time and waiting between the retries.
+	// The real clock is used if it's not set.
+	Clock clock.Clock
+}
+
+// ExponentialBackoff returns a backoff policy which doubles the delay after each retry,
+// starting from the initial interval and capping it to the max interval.
+func ExponentialBackoff(initial, max time.Duration) Backoff {
+	return Backoff{
+		InitialInterval: initial,
+		MaxInterval:     max,
+		Multiplier:      2,
+	}
+}
+
+// DecorrelatedJitterBackoff returns a backoff policy using the decorrelated jitter, in which
+// each delay is a random value between the initial interval and three times the previous delay,
+// capped to the max interval. This spreads the retries of the concurrent clients over time.
+func DecorrelatedJitterBackoff(initial, max time.Duration) Backoff {
+	return Backoff{
+		InitialInterval: initial,
+		MaxInterval:     max,
+		Jitter:          true,
+	}
+}
+
+// next returns the delay following the previous one, which is zero before the first retry.
+func (b Backoff) next(prev time.Duration) time.Duration {
+	var delay time.Duration
+
+	switch {
+	case b.Jitter:
+		if prev < b.InitialInterval {
+			prev = b.InitialInterval
+		}
+		delay = b.InitialInterval
+		if upper := 3 * prev; upper > b.InitialInterval {
+			delay += time.Duration(rand.Int63n(int64(upper - b.InitialInterval)))
+		}
+	case prev == 0:
+		delay = b.InitialInterval
+	default:
+		mult := b.Multiplier
+		if mult <= 0 {
+			mult = 2
+		}
+		if next := float64(prev) * mult; next < math.MaxInt64 {
+			delay = time.Duration(next)
+		} else {
+			delay = math.MaxInt64
+		}
+	}
+
+	if b.MaxInterval > 0 && delay > b.MaxInterval {
+		delay = b.MaxInterval
+	}
+	return delay
+}
+
+// clock returns the clock of the backoff policy, or the real clock if it's not set.
+func (b Backoff) clock() clock.Clock {
+	if b.Clock != nil {
+		return b.Clock
+	}
+	return clock.New()
+}
+
+// permanentError wraps an error which should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps the error returned by a retried function to signal that the operation should not be retried.
+// The retry functions stop at the first permanent error and return the wrapped error.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// permanentCause reports whether the error is permanent and returns the error wrapped by Permanent.
+func permanentCause(err error) (error, bool) {
+	var perr *permanentError
+	if errors.As(err, &perr) {
+		return perr.err, true
+	}
+	return err, false
+}
+
+// RetryWithBackoff tries to invoke the callback function `n` times, waiting between the calls according to the backoff policy.
+// It runs until the number of attempts is reached, the callback function succeeds or returns a permanent error,
+// or the maximum elapsed time of the backoff policy would be exceeded.
+func (v RType[T]) RetryWithBackoff(n int, b Backoff, fn func(T) error) (int, error) {
+	return v.retry(context.Background(), n, b, func(_ context.Context, input T) error {
+		return fn(input)
+	})
+}
+
+// RetryCtx is like RetryWithBackoff, but it also stops once the context is canceled, in which case
+// the context error is returned. The context is passed to the callback function on each invocation.
+// For a constant delay between the calls use a backoff policy with a Multiplier of 1.
+func (v RType[T]) RetryCtx(ctx context.Context, n int, b Backoff, fn func(context.Context, T) error) (int, error) {
+	return v.retry(ctx, n, b, fn)
+}
+
+// retry implements the retry logic shared by RetryWithBackoff and RetryCtx.
+func (v RType[T]) retry(ctx context.Context, n int, b Backoff, fn func(context.Context, T) error) (int, error) {
+	var (
+		err     error
+		attempt int
+		delay   time.Duration
+	)
+
+	if n < 0 {
+		return attempt, fmt.Errorf("the number of attempts should be a positive number, got %v", n)
+	}
+
+	clk := b.clock()
+	start := clk.Now()
+	for attempt < n {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return attempt, ctxErr
+		}
+		if err = fn(ctx, v.Input); err == nil {
+			return attempt, nil
+		}
+		if cause, ok := permanentCause(err); ok {
+			return attempt, cause
+		}
+		if attempt++; attempt == n {
+			break
+		}
+
+		delay = b.next(delay)
+		if b.MaxElapsedTime > 0 && clk.Since(start)+delay > b.MaxElapsedTime {
+			break
+		}
+		if b.OnRetry != nil {
+			b.OnRetry(attempt, err, delay)
+		}
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-clk.After(delay):
+		}
+	}
+
+	return attempt, err
+}
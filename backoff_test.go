@@ -0,0 +1,190 @@
+package gogu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff_Exponential(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+	errFailed := errors.New("failed")
+
+	var delays []time.Duration
+	b := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+	b.Clock = clk
+	b.OnRetry = func(attempt int, err error, delay time.Duration) {
+		assert.Equal(len(delays)+1, attempt)
+		assert.ErrorIs(err, errFailed)
+		delays = append(delays, delay)
+	}
+
+	type result struct {
+		attempts int
+		err      error
+	}
+	done := make(chan result)
+	go func() {
+		rt := RType[int]{Input: 1}
+		attempts, err := rt.RetryWithBackoff(6, b, func(int) error {
+			return errFailed
+		})
+		done <- result{attempts, err}
+	}()
+
+	expected := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Millisecond,
+		50 * time.Millisecond,
+		50 * time.Millisecond,
+	}
+	for _, d := range expected {
+		clk.BlockUntil(1)
+		clk.Advance(d)
+	}
+
+	res := <-done
+	assert.Equal(6, res.attempts)
+	assert.ErrorIs(res.err, errFailed)
+	assert.Equal(expected, delays)
+}
+
+func TestBackoff_Jitter(t *testing.T) {
+	assert := assert.New(t)
+
+	initial, max := 10*time.Millisecond, 100*time.Millisecond
+	b := DecorrelatedJitterBackoff(initial, max)
+
+	var delay time.Duration
+	for i := 0; i < 100; i++ {
+		prev := delay
+		if prev < initial {
+			prev = initial
+		}
+		delay = b.next(delay)
+		assert.GreaterOrEqual(delay, initial)
+		assert.LessOrEqual(delay, 3*prev)
+		assert.LessOrEqual(delay, max)
+	}
+}
+
+func TestBackoff_MaxElapsedTime(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+	b := Backoff{
+		InitialInterval: 10 * time.Millisecond,
+		MaxElapsedTime:  35 * time.Millisecond,
+		Clock:           clk,
+	}
+
+	done := make(chan int)
+	go func() {
+		rt := RType[int]{Input: 1}
+		attempts, err := rt.RetryWithBackoff(10, b, func(int) error {
+			return errors.New("failed")
+		})
+		assert.Error(err)
+		done <- attempts
+	}()
+
+	// The third retry would be scheduled after 30ms+40ms, exceeding the maximum elapsed time.
+	clk.BlockUntil(1)
+	clk.Advance(10 * time.Millisecond)
+	clk.BlockUntil(1)
+	clk.Advance(20 * time.Millisecond)
+
+	assert.Equal(3, <-done)
+}
+
+func TestBackoff_Permanent(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(Permanent(nil))
+
+	errFatal := errors.New("fatal")
+	calls := 0
+	fn := func(int) error {
+		calls++
+		if calls == 2 {
+			return Permanent(errFatal)
+		}
+		return errors.New("temporary")
+	}
+
+	rt := RType[int]{Input: 1}
+	attempts, err := rt.Retry(5, fn)
+	assert.Equal(1, attempts)
+	assert.Equal(2, calls)
+	assert.Equal(errFatal, err)
+
+	calls = 0
+	_, attempts, err = rt.RetryWithDelay(5, time.Millisecond, func(_ time.Duration, v int) error {
+		return fn(v)
+	})
+	assert.Equal(1, attempts)
+	assert.Equal(2, calls)
+	assert.Equal(errFatal, err)
+
+	calls = 0
+	clk := clock.NewFake(time.Now())
+	b := ExponentialBackoff(time.Millisecond, time.Millisecond)
+	b.Clock = clk
+	go func() {
+		clk.BlockUntil(1)
+		clk.Advance(time.Millisecond)
+	}()
+	attempts, err = rt.RetryWithBackoff(5, b, fn)
+	assert.Equal(1, attempts)
+	assert.Equal(2, calls)
+	assert.Equal(errFatal, err)
+}
+
+func TestBackoff_RetryCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+	b := ExponentialBackoff(time.Second, time.Minute)
+	b.Clock = clk
+
+	ctx, cancel := context.WithCancel(context.Background())
+	type ctxKey struct{}
+	ctx = context.WithValue(ctx, ctxKey{}, "value")
+
+	calls := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		rt := RType[int]{Input: 1}
+		attempts, err := rt.RetryCtx(ctx, 10, b, func(ctx context.Context, _ int) error {
+			calls++
+			assert.Equal("value", ctx.Value(ctxKey{}))
+			return errors.New("failed")
+		})
+		assert.Equal(2, attempts)
+		assert.ErrorIs(err, context.Canceled)
+	}()
+
+	clk.BlockUntil(1)
+	clk.Advance(time.Second)
+	clk.BlockUntil(1)
+	cancel()
+	<-done
+	assert.Equal(2, calls)
+
+	rt := RType[int]{Input: 1}
+	attempts, err := rt.RetryCtx(ctx, 10, b, func(context.Context, int) error {
+		assert.Fail("the function should not be invoked with a canceled context")
+		return nil
+	})
+	assert.Equal(0, attempts)
+	assert.ErrorIs(err, context.Canceled)
+}
@@ -0,0 +1,242 @@
+package cache
+
+// ARCCache implements a fixed size Adaptive Replacement Cache. It tracks both the recently and the frequently
+// used values, together with the keys recently evicted from each of them (called ghost entries).
+// The ghost hits are used for continuously adapting the target size of the two lists to the access pattern.
+type ARCCache[K comparable, V any] struct {
+	t1, t2    *lruMap[K, V]        // the recently and the frequently used values
+	b1, b2    *lruMap[K, struct{}] // the keys evicted from t1 and t2
+	p         int                  // the target size of t1
+	size      int
+	stats     *statsCounter
+	admission *tinyLFU[K]
+	window    *admissionWindow[K, V]
+}
+
+// NewARC initializes a new ARC cache. The WithStats option enables collecting the cache statistics
+// and the WithTinyLFU option enables the W-TinyLFU admission policy.
+func NewARC[K comparable, V any](size int, opts ...Option) (*ARCCache[K, V], error) {
+	if size <= 0 {
+		return nil, errInvalidSize
+	}
+
+	cfg := newConfig(opts)
+	window := newAdmissionWindow[K, V](cfg.tinyLFU, size)
+	return &ARCCache[K, V]{
+		t1:        newLRUMap[K, V](),
+		t2:        newLRUMap[K, V](),
+		b1:        newLRUMap[K, struct{}](),
+		b2:        newLRUMap[K, struct{}](),
+		size:      size - window.capacity(),
+		stats:     newStatsCounter(cfg.stats),
+		admission: newTinyLFU[K](cfg.tinyLFU, size),
+		window:    window,
+	}, nil
+}
+
+// Add adds a value to the cache. If a value is evicted, this value and the key for it is returned.
+// In case the W-TinyLFU admission policy is enabled, the new value enters the admission window and the value
+// pushed out of it might not be admitted into the cache, in which case it is returned as the evicted value.
+func (c *ARCCache[K, V]) Add(key K, value V) (oldestKey K, oldestValue V, removed bool) {
+	c.admission.record(key)
+
+	if _, ok := c.t1.remove(key); ok {
+		c.t2.pushFront(key, value)
+		c.stats.update()
+		return
+	}
+	if nd, ok := c.t2.get(key); ok {
+		nd.value = value
+		c.t2.moveFront(nd)
+		c.stats.update()
+		return
+	}
+	if c.window.update(key, value) {
+		c.stats.update()
+		return
+	}
+
+	// The new value enters the admission window and the value pushed out of it competes for the cache.
+	// Without an admission window, the new value itself competes for the cache.
+	key, value, ok := c.window.push(key, value)
+	if !ok {
+		return
+	}
+
+	b1Hit, b2Hit := c.b1.contains(key), c.b2.contains(key)
+
+	p := c.p
+	switch {
+	case b1Hit:
+		// A recently evicted value is requested again, so the recent list should grow.
+		delta := 1
+		if b1, b2 := c.b1.len(), c.b2.len(); b1 < b2 {
+			delta = b2 / b1
+		}
+		if p += delta; p > c.size {
+			p = c.size
+		}
+	case b2Hit:
+		// A frequently used value is requested again, so the frequent list should grow.
+		delta := 1
+		if b1, b2 := c.b1.len(), c.b2.len(); b2 < b1 {
+			delta = b1 / b2
+		}
+		if p -= delta; p < 0 {
+			p = 0
+		}
+	}
+
+	if c.t1.len()+c.t2.len() >= c.size {
+		if victim, ok := c.victim(p, b2Hit); ok && !c.admission.admit(key, victim) {
+			c.stats.reject()
+			return c.window.rejected(key, value)
+		}
+	}
+
+	c.p = p
+	oldestKey, oldestValue, removed = c.replace(b2Hit)
+	switch {
+	case b1Hit:
+		c.b1.remove(key)
+		c.t2.pushFront(key, value)
+	case b2Hit:
+		c.b2.remove(key)
+		c.t2.pushFront(key, value)
+	default:
+		if c.b1.len() > c.size-c.p {
+			c.b1.removeOldest()
+		}
+		if c.b2.len() > c.p {
+			c.b2.removeOldest()
+		}
+		c.t1.pushFront(key, value)
+	}
+	c.stats.set()
+
+	return
+}
+
+// evictRecent reports whether the next value should be evicted from t1, given the target size of t1.
+func (c *ARCCache[K, V]) evictRecent(p int, b2Hit bool) bool {
+	t1 := c.t1.len()
+	return t1 > 0 && (t1 > p || (t1 == p && b2Hit))
+}
+
+// victim returns the key of the value which would be evicted next, given the target size of t1.
+func (c *ARCCache[K, V]) victim(p int, b2Hit bool) (K, bool) {
+	if c.evictRecent(p, b2Hit) {
+		return c.t1.oldest()
+	}
+	return c.t2.oldest()
+}
+
+// replace evicts a value in case the cache is full, either from t1 or t2 depending on the target size of t1.
+// The key of the evicted value is moved into the corresponding ghost list.
+func (c *ARCCache[K, V]) replace(b2Hit bool) (key K, value V, removed bool) {
+	if c.t1.len()+c.t2.len() < c.size {
+		return
+	}
+
+	if c.evictRecent(c.p, b2Hit) {
+		if key, value, removed = c.t1.removeOldest(); removed {
+			c.b1.pushFront(key, struct{}{})
+		}
+	} else {
+		if key, value, removed = c.t2.removeOldest(); removed {
+			c.b2.pushFront(key, struct{}{})
+		}
+	}
+	if removed {
+		c.stats.evicted(EvictCapacity, 1)
+	}
+
+	return
+}
+
+// Get returns the element for the key if the element is present in the cache.
+// The elements accessed again are moved into the frequently used list.
+func (c *ARCCache[K, V]) Get(key K) (value V, available bool) {
+	c.admission.record(key)
+	if value, ok := c.t1.remove(key); ok {
+		c.t2.pushFront(key, value)
+		c.stats.hit()
+		return value, true
+	}
+	if nd, ok := c.t2.get(key); ok {
+		c.t2.moveFront(nd)
+		c.stats.hit()
+		return nd.value, true
+	}
+	if value, available = c.window.get(key); available {
+		c.stats.hit()
+		return
+	}
+	c.stats.miss()
+	return
+}
+
+// Peek returns the element for the key if the element is present in the cache, without recording the access.
+func (c *ARCCache[K, V]) Peek(key K) (value V, available bool) {
+	if nd, ok := c.t1.get(key); ok {
+		return nd.value, true
+	}
+	if nd, ok := c.t2.get(key); ok {
+		return nd.value, true
+	}
+	return c.window.peek(key)
+}
+
+// Contains reports whether the key is present in the cache, without recording the access.
+func (c *ARCCache[K, V]) Contains(key K) bool {
+	return c.t1.contains(key) || c.t2.contains(key) || c.window.contains(key)
+}
+
+// Remove removes an element from the cache denoted by the key. The value removed is returned.
+func (c *ARCCache[K, V]) Remove(key K) (value V, removed bool) {
+	if value, removed = c.t1.remove(key); !removed {
+		value, removed = c.t2.remove(key)
+	}
+	if !removed {
+		value, removed = c.window.remove(key)
+	}
+	c.b1.remove(key)
+	c.b2.remove(key)
+	if removed {
+		c.stats.evicted(EvictDeleted, 1)
+	}
+	return
+}
+
+// Count returns the number of the current values from the cache.
+func (c *ARCCache[K, V]) Count() int {
+	return c.t1.len() + c.t2.len() + c.window.len()
+}
+
+// Flush clears all values from the cache.
+func (c *ARCCache[K, V]) Flush() {
+	c.stats.evicted(EvictFlushed, c.Count())
+	c.t1.reset()
+	c.t2.reset()
+	c.b1.reset()
+	c.b2.reset()
+	c.p = 0
+	c.window.reset()
+	c.admission.reset()
+}
+
+// Stats returns a snapshot of the cache statistics.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (c *ARCCache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (c *ARCCache[K, V]) ResetStats() {
+	c.stats.reset()
+}
+
+// AsStore returns an adapter exposing the ARC cache through the Store interface.
+func (c *ARCCache[K, V]) AsStore() Store[K, V] {
+	return boundedStore[K, V]{c}
+}
@@ -0,0 +1,35 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestARCCache_Adaptation(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewARC[int, int](4)
+	for i := 0; i < 4; i++ {
+		c.Add(i, i)
+	}
+	assert.Equal(4, c.t1.len())
+
+	// The values accessed again are moved into the frequently used list.
+	c.Get(0)
+	c.Get(1)
+	assert.Equal(2, c.t2.len())
+
+	// The recently used values are evicted first into the ghost list.
+	key, _, removed := c.Add(4, 4)
+	assert.True(removed)
+	assert.Equal(2, key)
+	assert.True(c.b1.contains(2))
+
+	// A ghost hit grows the target size of the recently used list.
+	c.Add(2, 2)
+	assert.Equal(1, c.p)
+	assert.True(c.t2.contains(2))
+	assert.False(c.b1.contains(2))
+	assert.Equal(4, c.Count())
+}
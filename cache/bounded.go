@@ -0,0 +1,133 @@
+package cache
+
+// Bounded is the common API of the fixed size caches: LRUCache, LFUCache, TwoQueueCache, ARCCache and S3FIFOCache.
+// Like LRUCache, the implementations are not safe for concurrent use.
+type Bounded[K comparable, V any] interface {
+	// Add adds a value to the cache. If a value is evicted for making room to the new one,
+	// the evicted key/value pair is returned.
+	Add(key K, value V) (oldestKey K, oldestValue V, removed bool)
+	// Get returns the value stored under the key and records the access.
+	Get(key K) (value V, available bool)
+	// Peek returns the value stored under the key without recording the access.
+	Peek(key K) (value V, available bool)
+	// Contains reports whether the key is present in the cache, without recording the access.
+	Contains(key K) bool
+	// Remove removes the value stored under the key. The value removed is returned.
+	Remove(key K) (value V, removed bool)
+	// Count returns the number of values from the cache.
+	Count() int
+	// Flush removes all the values from the cache.
+	Flush()
+
+	StatsProvider
+}
+
+var (
+	_ Bounded[string, any] = (*LRUCache[string, any])(nil)
+	_ Bounded[string, any] = (*LFUCache[string, any])(nil)
+	_ Bounded[string, any] = (*TwoQueueCache[string, any])(nil)
+	_ Bounded[string, any] = (*ARCCache[string, any])(nil)
+	_ Bounded[string, any] = (*S3FIFOCache[string, any])(nil)
+)
+
+// lruMap is a doubly linked list indexed by a map. The most recently added or moved entries are at the front.
+// It is the building block of the queues used by the bounded caches.
+type lruMap[K comparable, V any] struct {
+	list  *lruList[K, V]
+	nodes map[K]*node[K, V]
+}
+
+func newLRUMap[K comparable, V any]() *lruMap[K, V] {
+	return &lruMap[K, V]{
+		list:  newLRUList[K, V](),
+		nodes: make(map[K]*node[K, V]),
+	}
+}
+
+func (m *lruMap[K, V]) get(key K) (*node[K, V], bool) {
+	nd, ok := m.nodes[key]
+	return nd, ok
+}
+
+func (m *lruMap[K, V]) contains(key K) bool {
+	_, ok := m.nodes[key]
+	return ok
+}
+
+func (m *lruMap[K, V]) pushFront(key K, value V) {
+	m.nodes[key] = m.list.addFront(key, value)
+}
+
+func (m *lruMap[K, V]) moveFront(nd *node[K, V]) {
+	m.list.moveFront(nd)
+}
+
+func (m *lruMap[K, V]) remove(key K) (value V, removed bool) {
+	if nd, ok := m.nodes[key]; ok {
+		delete(m.nodes, key)
+		m.list.remove(nd)
+		return nd.value, true
+	}
+	return
+}
+
+func (m *lruMap[K, V]) removeOldest() (key K, value V, removed bool) {
+	if nd := m.list.last(); nd != &m.list.root {
+		delete(m.nodes, nd.key)
+		m.list.remove(nd)
+		return nd.key, nd.value, true
+	}
+	return
+}
+
+// oldest returns the key of the entry from the back of the list.
+func (m *lruMap[K, V]) oldest() (key K, ok bool) {
+	if nd := m.list.last(); nd != &m.list.root {
+		return nd.key, true
+	}
+	return
+}
+
+func (m *lruMap[K, V]) len() int {
+	return m.list.len
+}
+
+func (m *lruMap[K, V]) reset() {
+	m.list = newLRUList[K, V]()
+	m.nodes = make(map[K]*node[K, V])
+}
+
+// boundedStore adapts the fixed size caches to the Store interface.
+type boundedStore[K comparable, V any] struct {
+	c Bounded[K, V]
+}
+
+func (s boundedStore[K, V]) Get(key K) (V, bool) {
+	return s.c.Get(key)
+}
+
+func (s boundedStore[K, V]) Set(key K, val V) error {
+	s.c.Add(key, val)
+	return nil
+}
+
+func (s boundedStore[K, V]) Delete(key K) bool {
+	_, removed := s.c.Remove(key)
+	return removed
+}
+
+func (s boundedStore[K, V]) Len() int {
+	return s.c.Count()
+}
+
+func (s boundedStore[K, V]) Clear() {
+	s.c.Flush()
+}
+
+func (s boundedStore[K, V]) Stats() Stats {
+	return s.c.Stats()
+}
+
+func (s boundedStore[K, V]) ResetStats() {
+	s.c.ResetStats()
+}
@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// boundedCaches returns a new instance of each bounded cache implementation.
+func boundedCaches[K comparable, V any](size int, opts ...Option) map[string]Bounded[K, V] {
+	lru, _ := NewLRU[K, V](size, opts...)
+	lfu, _ := NewLFU[K, V](size, opts...)
+	twoq, _ := NewTwoQueue[K, V](size, opts...)
+	arc, _ := NewARC[K, V](size, opts...)
+	s3fifo, _ := NewS3FIFO[K, V](size, opts...)
+
+	return map[string]Bounded[K, V]{
+		"LRU":     lru,
+		"LFU":     lfu,
+		"2Q":      twoq,
+		"ARC":     arc,
+		"S3-FIFO": s3fifo,
+	}
+}
+
+func TestBounded_InvalidSize(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewLFU[string, int](0)
+	assert.Error(err)
+	_, err = NewTwoQueue[string, int](0)
+	assert.Error(err)
+	_, err = NewARC[string, int](-1)
+	assert.Error(err)
+	_, err = NewS3FIFO[string, int](0)
+	assert.Error(err)
+}
+
+func TestBounded_API(t *testing.T) {
+	for name, c := range boundedCaches[int, int](10, WithStats()) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			_, ok := c.Get(1)
+			assert.False(ok)
+
+			for i := 0; i < 10; i++ {
+				_, _, removed := c.Add(i, i)
+				assert.False(removed)
+			}
+			assert.Equal(10, c.Count())
+
+			// Updating an existing value doesn't evict anything.
+			_, _, removed := c.Add(5, 50)
+			assert.False(removed)
+			val, ok := c.Peek(5)
+			assert.True(ok)
+			assert.Equal(50, val)
+			assert.True(c.Contains(5))
+
+			val, ok = c.Get(5)
+			assert.True(ok)
+			assert.Equal(50, val)
+
+			// The cache never grows beyond its size.
+			for i := 10; i < 100; i++ {
+				c.Add(i, i)
+				assert.LessOrEqual(c.Count(), 10)
+			}
+			assert.Equal(10, c.Count())
+
+			c.Add(1000, 1000)
+			val, ok = c.Remove(1000)
+			assert.True(ok)
+			assert.Equal(1000, val)
+			_, ok = c.Remove(1000)
+			assert.False(ok)
+			assert.False(c.Contains(1000))
+			assert.Equal(9, c.Count())
+
+			stats := c.Stats()
+			assert.Equal(uint64(1), stats.Hits)
+			assert.Equal(uint64(1), stats.Misses)
+			assert.Equal(uint64(1), stats.Updates)
+			assert.Equal(uint64(101), stats.Sets)
+
+			c.Flush()
+			assert.Equal(0, c.Count())
+			_, ok = c.Peek(5)
+			assert.False(ok)
+
+			c.ResetStats()
+			assert.Equal(Stats{}, c.Stats())
+		})
+	}
+}
+
+func TestBounded_ScanResistance(t *testing.T) {
+	assert := assert.New(t)
+
+	// The hot keys are accessed repeatedly, then a long scan is performed.
+	// The scan resistant caches should still hold most of the hot keys after the scan.
+	for _, name := range []string{"LFU", "2Q", "ARC", "S3-FIFO"} {
+		c := boundedCaches[int, int](100)[name]
+		for n := 0; n < 3; n++ {
+			for i := 0; i < 50; i++ {
+				if _, ok := c.Get(i); !ok {
+					c.Add(i, i)
+				}
+			}
+		}
+		for i := 1000; i < 2000; i++ {
+			c.Add(i, i)
+		}
+
+		var hot int
+		for i := 0; i < 50; i++ {
+			if c.Contains(i) {
+				hot++
+			}
+		}
+		assert.GreaterOrEqual(hot, 40, name)
+	}
+}
+
+// zipfTrace returns n keys following a Zipf distribution over the keyspace.
+func zipfTrace(n int, keyspace uint64) []uint64 {
+	r := rand.New(rand.NewSource(1))
+	z := rand.NewZipf(r, 1.01, 1, keyspace-1)
+
+	trace := make([]uint64, n)
+	for i := range trace {
+		trace[i] = z.Uint64()
+	}
+	return trace
+}
+
+// scanTrace returns a Zipf trace interleaved with sequential scans over keys which are never accessed again.
+func scanTrace(n int, keyspace uint64) []uint64 {
+	trace := zipfTrace(n, keyspace)
+	next := keyspace
+
+	for i := 0; i < len(trace); i += 10000 {
+		for j := i; j < i+2000 && j < len(trace); j++ {
+			trace[j] = next
+			next++
+		}
+	}
+	return trace
+}
+
+// replay runs the trace against the cache, adding the missing keys, and returns the hit ratio.
+func replay(c Bounded[uint64, uint64], trace []uint64) float64 {
+	var hits int
+
+	for _, key := range trace {
+		if _, ok := c.Get(key); ok {
+			hits++
+			continue
+		}
+		c.Add(key, key)
+	}
+	return float64(hits) / float64(len(trace))
+}
+
+func benchmarkTrace(b *testing.B, trace []uint64) {
+	for _, name := range []string{"LRU", "LFU", "2Q", "ARC", "S3-FIFO"} {
+		for _, admission := range []bool{false, true} {
+			var opts []Option
+
+			bench := name
+			if admission {
+				opts = append(opts, WithTinyLFU())
+				bench += "+TinyLFU"
+			}
+			b.Run(bench, func(b *testing.B) {
+				var ratio float64
+
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					ratio = replay(boundedCaches[uint64, uint64](1000, opts...)[name], trace)
+				}
+				b.ReportMetric(ratio*100, "hit%")
+			})
+		}
+	}
+}
+
+func BenchmarkBounded_Zipf(b *testing.B) {
+	benchmarkTrace(b, zipfTrace(100000, 100000))
+}
+
+func BenchmarkBounded_Scan(b *testing.B) {
+	benchmarkTrace(b, scanTrace(100000, 100000))
+}
@@ -7,9 +7,13 @@ package cache
 import (
 	"errors"
 	"fmt"
+	"io"
 	"runtime"
 	"sync"
 	"time"
+
+	"github.com/esimov/gogu/clock"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
@@ -17,35 +21,134 @@ const (
 	DefaultExpiration time.Duration = 0
 )
 
+// ErrClosed is returned by the cache operations invoked after the cache has been closed.
+var ErrClosed = errors.New("cache is closed")
+
 // Item holds the cache object (which could be of any type) and an expiration time.
 // The expiration time defines the object lifetime.
 type Item[V any] struct {
 	object     V
 	expiration int64
+	ttl        time.Duration
+	cost       int64
+	tags       []string
+}
+
+// EvictReason describes the cause of an item being removed from the cache.
+type EvictReason int
+
+const (
+	// EvictExpired is reported when an expired item is removed from the cache.
+	EvictExpired EvictReason = iota
+	// EvictDeleted is reported when an item is removed with the Delete method.
+	EvictDeleted
+	// EvictFlushed is reported for every item removed by the Flush method.
+	EvictFlushed
+	// EvictReplaced is reported when an existing item is overwritten with a new value.
+	EvictReplaced
+	// EvictCapacity is reported when an item is removed to make room for a new one in a full cache.
+	EvictCapacity
+)
+
+// String returns the textual representation of the eviction reason.
+func (r EvictReason) String() string {
+	switch r {
+	case EvictExpired:
+		return "expired"
+	case EvictDeleted:
+		return "deleted"
+	case EvictFlushed:
+		return "flushed"
+	case EvictReplaced:
+		return "replaced"
+	case EvictCapacity:
+		return "capacity"
+	}
+	return fmt.Sprintf("EvictReason(%d)", int(r))
 }
 
-type cache[K ~string, V any] struct {
+// eviction holds the details of a removed item until the eviction callback is invoked.
+type eviction[K comparable, V any] struct {
+	key    K
+	val    V
+	reason EvictReason
+}
+
+type cache[K comparable, V any] struct {
 	mu         sync.RWMutex
 	items      map[K]*Item[V]
 	done       chan struct{}
+	stopped    chan struct{}
 	expTime    time.Duration
 	cleanupInt time.Duration
+	onEvicted  func(K, V, EvictReason)
+	group      singleflight.Group
+	staleTTL   time.Duration
+	refreshErr func(K, error)
+	stats      *statsCounter
+	sliding    bool
+	tagIndex   map[string]map[K]struct{}
+	closed     bool
+	clock      clock.Clock
+
+	// The fields below are used only by the bounded caches.
+	maxEntries  int
+	maxCost     int64
+	costFn      func(V) int64
+	cost        int64
+	policy      evictPolicy[K]
+	trackAccess bool
 }
 
 // Cache is a publicly available struct type, which incorporates the
 // unexported cache struct type holding the cache components.
-type Cache[K ~string, V any] struct {
+type Cache[K comparable, V any] struct {
 	*cache[K, V]
 }
 
+var (
+	_ io.Closer     = (*Cache[string, any])(nil)
+	_ StatsProvider = (*Cache[string, any])(nil)
+)
+
 // newCache has a local scope only. `New` will be used for the cache instantiation outside this package.
-func newCache[K ~string, V any](expTime, cleanupInt time.Duration, item map[K]*Item[V]) *cache[K, V] {
+func newCache[K comparable, V any](expTime, cleanupInt time.Duration, item map[K]*Item[V], cfg config) *cache[K, V] {
 	c := &cache[K, V]{
 		mu:         sync.RWMutex{},
 		items:      item,
 		expTime:    expTime,
 		cleanupInt: cleanupInt,
 		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+		staleTTL:   cfg.staleTTL,
+		stats:      newStatsCounter(cfg.stats),
+		sliding:    cfg.sliding,
+		maxEntries: cfg.maxEntries,
+		maxCost:    cfg.maxCost,
+		clock:      cfg.clock,
+	}
+	if cleanupInt <= 0 {
+		// There is no cleanup goroutine to wait for on Close.
+		close(c.stopped)
+	}
+
+	if cfg.cost != nil {
+		fn, ok := cfg.cost.(func(V) int64)
+		if !ok {
+			panic(fmt.Sprintf("cache: the cost function %T does not match the cache value type", cfg.cost))
+		}
+		c.costFn = fn
+	}
+	if cfg.refreshErr != nil {
+		fn, ok := cfg.refreshErr.(func(K, error))
+		if !ok {
+			panic(fmt.Sprintf("cache: the refresh error handler %T does not match the cache key type", cfg.refreshErr))
+		}
+		c.refreshErr = fn
+	}
+	if c.maxEntries > 0 || c.maxCost > 0 {
+		c.policy = newEvictPolicy[K](cfg.policy)
+		c.trackAccess = cfg.policy == PolicyLRU
 	}
 	return c
 }
@@ -53,72 +156,110 @@ func newCache[K ~string, V any](expTime, cleanupInt time.Duration, item map[K]*I
 // New instantiates a cache struct which requires an expiration time and a cleanup interval.
 // The cache will be invalidated once the expiration time is reached.
 // If the expiration time is less than zero (or NoExpiration) the cache items will never expire and should be deleted manually.
+// The number of items and their total cost can be limited with the WithMaxEntries and WithMaxCost options.
 // A cleanup method is running in the background and removes the expired caches at a predefined interval.
-func New[K ~string, V any](expTime, cleanupTime time.Duration) *Cache[K, V] {
+// The cleanup goroutine should be stopped by calling the Close method once the cache is not needed anymore.
+// The cache behavior can be further customized with the provided options.
+func New[K comparable, V any](expTime, cleanupTime time.Duration, opts ...Option) *Cache[K, V] {
 	items := make(map[K]*Item[V])
-	c := newCache(expTime, cleanupTime, items)
+	c := newCache(expTime, cleanupTime, items, newConfig(opts))
+	C := &Cache[K, V]{c}
 
 	if cleanupTime > 0 {
 		go c.cleanup()
-		// We need to make sure that the goroutine responsible for the cache eviction stops after the cleanup.
-		// This is the reason why runtime.SetFinalizer is used.
-		// This method is invoked when the garbage collector finds an unreachable block ready to be collected.
-		runtime.SetFinalizer(c, stopCleanup[K, V])
+		// In case the cache is not closed explicitly, we need to make sure that the goroutine responsible
+		// for the cache eviction stops once the cache became unreachable. This is the reason why runtime.SetFinalizer
+		// is used as a fallback. The finalizer is attached to the outer struct, since the cleanup goroutine
+		// keeps a reference to the inner one, which would otherwise never become unreachable.
+		runtime.SetFinalizer(C, stopCleanup[K, V])
 	}
 
-	return &Cache[K, V]{c}
+	return C
 }
 
-// Set inserts a new item into the cache, but first verifies if an item with the same key already exists in the cache.
-// In case an item with the specified key already exists in the cache it will return an error.
-func (c *Cache[K, V]) Set(key K, val V, d time.Duration) error {
-	item, err := c.Get(key)
-	if item != nil && err == nil {
-		return fmt.Errorf("item with key '%v' already exists. Use the Update method", key)
+// Close stops the cleanup goroutine and marks the cache as closed. It returns once the cleanup goroutine has exited.
+// Any subsequent operation which returns an error will return ErrClosed, while Flush becomes a no-op.
+// Count, List and IsExpired keep reporting the items stored at the time the cache was closed.
+// Calling Close more than once also returns ErrClosed.
+func (c *Cache[K, V]) Close() error {
+	runtime.SetFinalizer(c, nil)
+
+	return c.close()
+}
+
+// close has a local scope only. It marks the cache as closed and stops the cleanup goroutine.
+func (c *cache[K, V]) close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
 	}
-	c.add(key, val, d)
+	c.closed = true
+	close(c.done)
+	c.mu.Unlock()
+
+	// The lock is released before waiting, since the cleanup goroutine might be in the middle of a DeleteExpired call.
+	<-c.stopped
 
 	return nil
 }
 
+// Set inserts a new item into the cache, but first verifies if an item with the same key already exists in the cache.
+// In case an item with the specified key already exists in the cache it will return an error.
+func (c *Cache[K, V]) Set(key K, val V, d time.Duration) error {
+	return c.SetWithTags(key, val, d)
+}
+
 // SetDefault adds a new item into the cache with the default expiration time.
 func (c *Cache[K, V]) SetDefault(key K, val V) error {
 	return c.Set(key, val, DefaultExpiration)
 }
 
-// add inserts a new item into the cache together with an expiration time.
+// add inserts a new item into the cache together with an expiration time and optionally some tags.
 // If the duration is 0 (or DefaultExpiration) the cache default expiration time is used.
 // If the duration is < 0 (or NoExpiration), the item never expires and should be removed manually.
-func (c *Cache[K, V]) add(key K, val V, d time.Duration) error {
-	var exp int64
-
-	if d == DefaultExpiration {
-		d = c.expTime
-	}
-	if d > 0 {
-		exp = time.Now().Add(d).UnixNano()
-	} else if d < 0 {
-		exp = int64(NoExpiration)
-	}
+func (c *Cache[K, V]) add(key K, val V, d time.Duration, tags ...string) error {
+	exp, ttl := c.expiration(d)
 
-	item, err := c.Get(key)
+	item, err := c.get(key)
 	if item != nil && err != nil {
 		return fmt.Errorf("item with key '%v' already exists", key)
 	}
 
-	switch any(val).(type) {
-	case string:
-		if len(any(val).(string)) == 0 {
-			return fmt.Errorf("value of type string cannot be empty")
-		}
+	cost, err := c.validate(key, val)
+	if err != nil {
+		return err
 	}
 
+	var evicted []eviction[K, V]
+
 	c.mu.Lock()
-	c.items[key] = &Item[V]{
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	if old, ok := c.items[key]; ok {
+		reason := EvictReplaced
+		if old.expired(c.now()) {
+			reason = EvictExpired
+		}
+		evicted = append(evicted, eviction[K, V]{key, old.object, reason})
+	}
+	if len(evicted) > 0 && evicted[0].reason == EvictReplaced {
+		c.stats.update()
+	} else {
+		c.stats.set()
+	}
+	c.store(key, &Item[V]{
 		object:     val,
 		expiration: exp,
-	}
+		ttl:        ttl,
+		cost:       cost,
+		tags:       tags,
+	})
+	evicted = append(evicted, c.evictOverflowExcept(key)...)
 	c.mu.Unlock()
+	c.notify(evicted)
 
 	return nil
 }
@@ -127,10 +268,27 @@ func (c *Cache[K, V]) add(key K, val V, d time.Duration) error {
 // If an item is expired it's considered as nonexistent, it will be evicted from the cache
 // when the purge method is invoked at the predefined interval.
 func (c *Cache[K, V]) Get(key K) (*Item[V], error) {
+	item, err := c.get(key)
+	if item != nil {
+		c.stats.hit()
+		c.accessed(key)
+	} else if err != ErrClosed {
+		c.stats.miss()
+	}
+
+	return item, err
+}
+
+// get has a local scope only. It looks up an item without recording the statistics.
+func (c *cache[K, V]) get(key K) (*Item[V], error) {
 	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return nil, ErrClosed
+	}
 	if item, ok := c.items[key]; ok {
 		if item.expiration > 0 {
-			now := time.Now().UnixNano()
+			now := c.now()
 			if now > item.expiration {
 				c.mu.RUnlock()
 				return nil, fmt.Errorf("item with key '%v' expired", key)
@@ -143,6 +301,24 @@ func (c *Cache[K, V]) Get(key K) (*Item[V], error) {
 	return nil, fmt.Errorf("item with key '%v' not found", key)
 }
 
+// copyItem returns a copy of an item which is not expired. The copy is made while holding the lock,
+// which means that it is not affected by the subsequent changes of the item (e.g. expiration renewal).
+func (c *cache[K, V]) copyItem(key K) (*Item[V], bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if item, ok := c.items[key]; ok && !item.expired(c.now()) {
+		cp := *item
+		return &cp, true
+	}
+	return nil, false
+}
+
+// expired reports whether the item has been expired at the provided unix time (in nanoseconds).
+func (it *Item[V]) expired(now int64) bool {
+	return it.expiration > 0 && now > it.expiration
+}
+
 // Val returns the effective value of the cache item.
 func (it *Item[V]) Val() V {
 	var v V
@@ -152,67 +328,327 @@ func (it *Item[V]) Val() V {
 	return v
 }
 
-// Update replaces a cache item with the new value.
+// Update replaces a cache item with the new value. The tags of the replaced item are kept.
 func (c *Cache[K, V]) Update(key K, val V, d time.Duration) error {
-	item, err := c.Get(key)
+	item, err := c.get(key)
 	if item != nil && err != nil {
 		return err
 	}
+	if item != nil {
+		return c.add(key, val, d, item.tags...)
+	}
 	return c.add(key, val, d)
 }
 
 // Delete removes a cache item.
 func (c *Cache[K, V]) Delete(key K) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	item, err := c.delete(key)
+	c.mu.Unlock()
 
-	return c.delete(key)
+	if err != nil {
+		return err
+	}
+	c.notify([]eviction[K, V]{{key, item.object, EvictDeleted}})
+
+	return nil
 }
 
-// delete has a local scope only.
-func (c *cache[K, V]) delete(key K) error {
-	if _, ok := c.items[key]; ok {
+// delete has a local scope only. It returns the removed item.
+func (c *cache[K, V]) delete(key K) (*Item[V], error) {
+	if item, ok := c.items[key]; ok {
 		delete(c.items, key)
+		c.untag(key, item.tags)
+		if c.policy != nil {
+			c.cost -= item.cost
+			c.policy.remove(key)
+		}
+
+		return item, nil
+	}
+
+	return nil, fmt.Errorf("item with key '%v' does not exists", key)
+}
+
+// store has a local scope only. It inserts the item into the cache (replacing the existing one)
+// and keeps the bookkeeping of the bounded caches up to date. The lock should be held by the caller.
+func (c *cache[K, V]) store(key K, item *Item[V]) {
+	old, ok := c.items[key]
+	c.items[key] = item
+
+	if ok {
+		c.untag(key, old.tags)
+	}
+	c.tag(key, item.tags)
+
+	if c.policy != nil {
+		if ok {
+			c.cost -= old.cost
+		}
+		c.cost += item.cost
+		c.policy.add(key, item.expiration)
+	}
+}
+
+// validate checks if the value can be stored in the cache and returns its cost.
+func (c *cache[K, V]) validate(key K, val V) (int64, error) {
+	switch any(val).(type) {
+	case string:
+		if len(any(val).(string)) == 0 {
+			return 0, fmt.Errorf("value of type string cannot be empty")
+		}
+	}
 
+	cost := c.itemCost(val)
+	if c.maxCost > 0 && cost > c.maxCost {
+		return 0, fmt.Errorf("the cost of item with key '%v' exceeds the maximum cost", key)
+	}
+
+	return cost, nil
+}
+
+// itemCost returns the cost of a value, which is 1 if no cost function has been provided.
+func (c *cache[K, V]) itemCost(val V) int64 {
+	if c.costFn != nil {
+		return c.costFn(val)
+	}
+	return 1
+}
+
+// overflow reports whether a bounded cache exceeds its limits.
+func (c *cache[K, V]) overflow() bool {
+	return (c.maxEntries > 0 && len(c.items) > c.maxEntries) ||
+		(c.maxCost > 0 && c.cost > c.maxCost)
+}
+
+// evictOverflow removes items according to the eviction policy, until the cache
+// fits again into its limits. The lock should be held by the caller.
+func (c *cache[K, V]) evictOverflow() []eviction[K, V] {
+	var evicted []eviction[K, V]
+
+	if c.policy == nil {
+		return evicted
+	}
+
+	now := c.now()
+	for c.overflow() {
+		key, ok := c.policy.victim()
+		if !ok {
+			break
+		}
+		item, err := c.delete(key)
+		if err != nil {
+			c.policy.remove(key)
+			continue
+		}
+		reason := EvictCapacity
+		if item.expired(now) {
+			reason = EvictExpired
+		}
+		evicted = append(evicted, eviction[K, V]{key, item.object, reason})
+	}
+
+	return evicted
+}
+
+// evictOverflowExcept is like evictOverflow, but it never evicts the item with the provided key,
+// which is the one just stored in the cache. Otherwise the nearest expiration policy would evict
+// a new item expiring sooner than the existing ones, while reporting it as successfully stored.
+func (c *cache[K, V]) evictOverflowExcept(key K) []eviction[K, V] {
+	if c.policy == nil || !c.overflow() {
 		return nil
 	}
 
-	return fmt.Errorf("item with key '%v' does not exists", key)
+	exp := c.items[key].expiration
+	c.policy.remove(key)
+	evicted := c.evictOverflow()
+	c.policy.add(key, exp)
+
+	return evicted
+}
+
+// expiration returns the expiration time of an item stored for the provided duration, together with its lifetime.
+// If the duration is 0 (or DefaultExpiration) the cache default expiration time is used.
+func (c *cache[K, V]) expiration(d time.Duration) (int64, time.Duration) {
+	var exp int64
+
+	if d == DefaultExpiration {
+		d = c.expTime
+	}
+	if d > 0 {
+		exp = c.clock.Now().Add(d).UnixNano()
+	} else if d < 0 {
+		exp = int64(NoExpiration)
+	}
+
+	return exp, d
+}
+
+// accessed marks the item as recently used, in case the eviction policy relies on it,
+// and renews its expiration time if the sliding expiration mode is enabled.
+func (c *cache[K, V]) accessed(key K) {
+	if !c.trackAccess && !c.sliding {
+		return
+	}
+
+	c.mu.Lock()
+	if item, ok := c.items[key]; ok {
+		if c.sliding && item.ttl > 0 && !item.expired(c.now()) {
+			c.renew(key, item, item.ttl)
+		}
+		if c.trackAccess {
+			c.policy.access(key)
+		}
+	}
+	c.mu.Unlock()
+}
+
+// renew sets the expiration time of an existing item. The lock should be held by the caller.
+// The item is replaced with a renewed copy instead of being modified in place, since the items
+// already returned by the cache can be read concurrently without holding the lock.
+func (c *cache[K, V]) renew(key K, item *Item[V], d time.Duration) {
+	renewed := *item
+	renewed.expiration, renewed.ttl = c.expiration(d)
+	c.items[key] = &renewed
+	if c.policy != nil {
+		c.policy.add(key, renewed.expiration)
+	}
+}
+
+// Touch renews the expiration time of an existing item which has not expired yet.
+// The duration has the same meaning as in the case of the Set method. The new duration is also
+// used for renewing the item expiration time on the subsequent reads in sliding expiration mode.
+func (c *Cache[K, V]) Touch(key K, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+	item, ok := c.items[key]
+	if !ok {
+		return fmt.Errorf("item with key '%v' not found", key)
+	}
+	if item.expired(c.now()) {
+		return fmt.Errorf("item with key '%v' expired", key)
+	}
+	c.renew(key, item, d)
+
+	return nil
 }
 
 // DeleteExpired removes all the expired items from the cache.
+// If the cache was created with the WithStaleWhileRevalidate option,
+// the items are removed only after their stale period also elapsed.
 func (c *cache[K, V]) DeleteExpired() error {
-	var err error
+	var (
+		err     error
+		evicted []eviction[K, V]
+	)
 
-	now := time.Now().UnixNano()
+	now := c.now()
 
 	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
 	for k, item := range c.items {
-		if now > item.expiration && item.expiration != int64(NoExpiration) {
-			if e := c.delete(k); e != nil {
+		if item.expired(now - int64(c.staleTTL)) {
+			if _, e := c.delete(k); e != nil {
 				err = errors.Join(err, e)
+				continue
 			}
+			evicted = append(evicted, eviction[K, V]{k, item.object, EvictExpired})
 		}
 
 	}
 	c.mu.Unlock()
+	c.notify(evicted)
 
 	return errors.Unwrap(err)
 }
 
-// Flush removes all the existing items in the cache.
+// Flush removes all the existing items in the cache. It has no effect on a closed cache.
 func (c *Cache[K, V]) Flush() {
 	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	items := c.items
 	c.items = make(map[K]*Item[V])
+	c.tagIndex = nil
+	if c.policy != nil {
+		c.cost = 0
+		c.policy.reset()
+	}
 	c.mu.Unlock()
+
+	if c.evictCallback() == nil {
+		c.stats.evicted(EvictFlushed, len(items))
+		return
+	}
+	evicted := make([]eviction[K, V], 0, len(items))
+	for k, item := range items {
+		evicted = append(evicted, eviction[K, V]{k, item.object, EvictFlushed})
+	}
+	c.notify(evicted)
+}
+
+// OnEvicted registers a callback function which is invoked each time an item is removed from the cache,
+// either because it expired, it was deleted or replaced, the cache was flushed or it reached its limits.
+// The callback is invoked outside the cache lock, which means it can safely access the cache again.
+// Passing a nil function removes the previously registered callback.
+func (c *Cache[K, V]) OnEvicted(fn func(key K, val V, reason EvictReason)) {
+	c.mu.Lock()
+	c.onEvicted = fn
+	c.mu.Unlock()
+}
+
+// evictCallback returns the registered eviction callback.
+func (c *cache[K, V]) evictCallback() func(K, V, EvictReason) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.onEvicted
 }
 
-// List returns the cache items which are not expired.
+// notify invokes the eviction callback for each evicted item. It must be called without holding the lock.
+func (c *cache[K, V]) notify(evicted []eviction[K, V]) {
+	if len(evicted) == 0 {
+		return
+	}
+	for _, e := range evicted {
+		c.stats.evicted(e.reason, 1)
+	}
+	if fn := c.evictCallback(); fn != nil {
+		for _, e := range evicted {
+			fn(e.key, e.val, e.reason)
+		}
+	}
+}
+
+// List returns the cache items which are not expired. The returned map is a copy
+// of the internal one, which means that it can be safely iterated while the cache is modified.
 func (c *Cache[K, V]) List() map[K]*Item[V] {
+	now := c.now()
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.items
+	items := make(map[K]*Item[V], len(c.items))
+	for k, item := range c.items {
+		if !item.expired(now) {
+			items[k] = item
+		}
+	}
+
+	return items
 }
 
 // Count returns the number of existing items in the cache.
@@ -236,33 +672,53 @@ func (c *Cache[K, V]) MapToCache(m map[K]V, d time.Duration) error {
 	return errors.Unwrap(err)
 }
 
-// IsExpired checks if a cache item is expired.
+// IsExpired checks if a cache item is expired. It returns false if the item does not exist,
+// which means that it was never added or it was already removed from the cache.
 func (c *Cache[K, V]) IsExpired(key K) bool {
-	item, err := c.Get(key)
-	if item != nil && err != nil {
-		if item.expiration > time.Now().UnixNano() {
-			return true
-		}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if item, ok := c.items[key]; ok {
+		return item.expired(c.now())
 	}
 	return false
 }
 
+// Stats returns a snapshot of the cache statistics.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (c *cache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (c *cache[K, V]) ResetStats() {
+	c.stats.reset()
+}
+
 // cleanup runs the cache cleanup function at the specified time interval an removes all the expired cache items.
+// The stopped channel is closed once the cleanup goroutine returns.
 func (c *cache[K, V]) cleanup() {
-	tick := time.NewTicker(c.cleanupInt)
+	defer close(c.stopped)
+
+	ticker := c.clock.NewTicker(c.cleanupInt)
+	defer ticker.Stop()
 
 	for {
 		select {
-		case <-tick.C:
+		case <-ticker.C:
 			c.DeleteExpired()
 		case <-c.done:
-			tick.Stop()
 			return
 		}
 	}
 }
 
+// now returns the current time of the cache clock in nanoseconds.
+func (c *cache[K, V]) now() int64 {
+	return c.clock.Now().UnixNano()
+}
+
 // stopCleanup stops the cleanup process once the cache item goes out of scope and became unreachable.
-func stopCleanup[K ~string, V any](c *cache[K, V]) {
-	c.done <- struct{}{}
+func stopCleanup[K comparable, V any](c *Cache[K, V]) {
+	c.close()
 }
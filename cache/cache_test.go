@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/esimov/gogu/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -300,3 +301,153 @@ func Example_expirationTime() {
 	// 2
 	// 1
 }
+
+func TestCache_Clock(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+	c := New[string, int](time.Minute, time.Hour, WithClock(clk))
+	defer c.Close()
+
+	assert.NoError(c.SetDefault("foo", 1))
+	assert.NoError(c.Set("bar", 2, 2*time.Minute))
+
+	clk.Advance(time.Minute + time.Second)
+	assert.True(c.IsExpired("foo"))
+	assert.False(c.IsExpired("bar"))
+	_, err := c.Get("foo")
+	assert.Error(err)
+	item, err := c.Get("bar")
+	assert.NoError(err)
+	assert.Equal(2, item.Val())
+
+	// The cleanup runs once the clock is advanced by the cleanup interval.
+	evicted := make(chan string, 2)
+	c.OnEvicted(func(key string, _ int, _ EvictReason) {
+		evicted <- key
+	})
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	assert.ElementsMatch([]string{"foo", "bar"}, []string{<-evicted, <-evicted})
+	assert.Equal(0, c.Count())
+}
+
+func TestCache_OnEvicted(t *testing.T) {
+	assert := assert.New(t)
+
+	type evicted struct {
+		key    string
+		val    int
+		reason EvictReason
+	}
+	var events []evicted
+
+	c := New[string, int](NoExpiration, 0)
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		events = append(events, evicted{key, val, reason})
+		// The callback is invoked outside the cache lock.
+		assert.Equal(c.Count(), c.Count())
+	})
+
+	c.Set("a", 1, DefaultExpiration)
+	c.Update("a", 2, DefaultExpiration)
+	assert.Equal([]evicted{{"a", 1, EvictReplaced}}, events)
+
+	events = nil
+	err := c.Delete("a")
+	assert.NoError(err)
+	assert.Equal([]evicted{{"a", 2, EvictDeleted}}, events)
+
+	events = nil
+	err = c.Delete("a")
+	assert.Error(err)
+	assert.Empty(events)
+
+	c.Set("b", 1, 1*time.Millisecond)
+	c.Set("c", 2, NoExpiration)
+	<-time.After(5 * time.Millisecond)
+	c.DeleteExpired()
+	assert.Equal([]evicted{{"b", 1, EvictExpired}}, events)
+
+	events = nil
+	c.Flush()
+	assert.Equal([]evicted{{"c", 2, EvictFlushed}}, events)
+	assert.Equal(0, c.Count())
+
+	events = nil
+	c.Set("d", 1, 1*time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+	c.Set("d", 2, NoExpiration)
+	assert.Equal([]evicted{{"d", 1, EvictExpired}}, events)
+
+	events = nil
+	c.OnEvicted(nil)
+	c.Delete("d")
+	assert.Empty(events)
+	assert.Equal("flushed", EvictFlushed.String())
+}
+
+func TestCache_Close(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, int](DefaultExpiration, 10*time.Millisecond)
+	c.Set("a", 1, DefaultExpiration)
+
+	err := c.Close()
+	assert.NoError(err)
+
+	// The cleanup goroutine should have already returned once Close returns.
+	select {
+	case <-c.stopped:
+	default:
+		t.Fatal("cleanup goroutine was not stopped")
+	}
+
+	assert.ErrorIs(c.Close(), ErrClosed)
+	assert.ErrorIs(c.Set("b", 2, DefaultExpiration), ErrClosed)
+	assert.ErrorIs(c.Update("a", 2, DefaultExpiration), ErrClosed)
+	assert.ErrorIs(c.Delete("a"), ErrClosed)
+	assert.ErrorIs(c.DeleteExpired(), ErrClosed)
+
+	item, err := c.Get("a")
+	assert.Nil(item)
+	assert.ErrorIs(err, ErrClosed)
+
+	c.Flush()
+	assert.Equal(1, c.Count())
+
+	c2 := New[string, int](DefaultExpiration, 0)
+	assert.NoError(c2.Close())
+}
+
+func TestCache_ComparableKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	type key struct {
+		tenant string
+		id     int
+	}
+
+	c1 := New[key, string](NoExpiration, 0)
+	c1.Set(key{"a", 1}, "foo", DefaultExpiration)
+	c1.Set(key{"a", 2}, "bar", DefaultExpiration)
+	item, err := c1.Get(key{"a", 1})
+	assert.NoError(err)
+	assert.Equal("foo", item.Val())
+	assert.Equal(2, c1.Count())
+
+	v, err := c1.GetOrLoad(key{"b", 1}, func(k key) (string, time.Duration, error) {
+		return k.tenant, DefaultExpiration, nil
+	})
+	assert.NoError(err)
+	assert.Equal("b", v)
+
+	c2, _ := NewSharded[int, int](4, NoExpiration, 0)
+	for i := 0; i < 10; i++ {
+		c2.Set(i, i*i, DefaultExpiration)
+	}
+	item2, err := c2.Get(3)
+	assert.NoError(err)
+	assert.Equal(9, item2.Val())
+	assert.Equal(10, c2.Count())
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"fmt"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Compute atomically replaces the value of an item with the result of the provided function.
+// The function receives the current value of the item and a flag reporting whether the item exists
+// (the expired items are considered nonexistent). It should return the new value and a flag reporting
+// whether the value should be stored. In case the flag is false, the item is removed from the cache.
+//
+// An existing item keeps its expiration time, while a newly created item gets the default expiration time.
+// The function is invoked while holding the cache lock, which means it must not access the cache.
+func (c *Cache[K, V]) Compute(key K, fn func(old V, ok bool) (V, bool)) (V, error) {
+	return c.compute(key, func(old V, ok bool) (V, computeOp) {
+		val, keep := fn(old, ok)
+		if keep {
+			return val, computeStore
+		}
+		return val, computeDelete
+	})
+}
+
+// computeOp is the operation applied by compute on the item, once the compute function returns.
+type computeOp int
+
+const (
+	// computeStore stores the value returned by the compute function.
+	computeStore computeOp = iota
+	// computeDelete removes the item from the cache.
+	computeDelete
+	// computeNoop leaves the cache untouched.
+	computeNoop
+)
+
+// compute has a local scope only. It implements Compute, but the compute function can also leave the cache untouched,
+// in which case the current value of the item is returned and no eviction callback or statistics counter is triggered.
+func (c *Cache[K, V]) compute(key K, fn func(old V, ok bool) (V, computeOp)) (V, error) {
+	var (
+		v       V
+		evicted []eviction[K, V]
+	)
+
+	now := c.now()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return v, ErrClosed
+	}
+
+	old, ok := c.items[key]
+	expired := ok && old.expired(now)
+	ok = ok && !expired
+
+	var oldVal V
+	if ok {
+		oldVal = old.object
+	}
+	val, op := fn(oldVal, ok)
+
+	if op == computeNoop {
+		c.mu.Unlock()
+		return oldVal, nil
+	}
+	if expired {
+		c.delete(key)
+		evicted = append(evicted, eviction[K, V]{key, old.object, EvictExpired})
+	}
+
+	if op == computeDelete {
+		if ok {
+			c.delete(key)
+			evicted = append(evicted, eviction[K, V]{key, old.object, EvictDeleted})
+		}
+		c.mu.Unlock()
+		c.notify(evicted)
+
+		return v, nil
+	}
+
+	cost, err := c.validate(key, val)
+	if err != nil {
+		c.mu.Unlock()
+		c.notify(evicted)
+
+		return v, err
+	}
+
+	item := &Item[V]{object: val, cost: cost}
+	if ok {
+		item.expiration, item.ttl, item.tags = old.expiration, old.ttl, old.tags
+		evicted = append(evicted, eviction[K, V]{key, old.object, EvictReplaced})
+		c.stats.update()
+	} else {
+		item.expiration, item.ttl = c.expiration(DefaultExpiration)
+		c.stats.set()
+	}
+	c.store(key, item)
+	evicted = append(evicted, c.evictOverflowExcept(key)...)
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return val, nil
+}
+
+// GetAndDelete atomically returns and removes a cache item. If the item is expired an error is returned,
+// but the item is removed from the cache anyway.
+func (c *Cache[K, V]) GetAndDelete(key K) (*Item[V], error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+
+	item, err := c.delete(key)
+	c.mu.Unlock()
+
+	if err != nil {
+		c.stats.miss()
+		return nil, fmt.Errorf("item with key '%v' not found", key)
+	}
+	if item.expired(c.now()) {
+		c.stats.miss()
+		c.notify([]eviction[K, V]{{key, item.object, EvictExpired}})
+		return nil, fmt.Errorf("item with key '%v' expired", key)
+	}
+	c.stats.hit()
+	c.notify([]eviction[K, V]{{key, item.object, EvictDeleted}})
+
+	return item, nil
+}
+
+// CompareAndSwap atomically replaces the value of an existing item with the new value,
+// but only if its current value is equal with the old value. It reports whether the swap took place.
+func CompareAndSwap[K, V comparable](c *Cache[K, V], key K, old, new V) (bool, error) {
+	var swapped bool
+
+	_, err := c.compute(key, func(cur V, ok bool) (V, computeOp) {
+		if ok && cur == old {
+			swapped = true
+			return new, computeStore
+		}
+		return cur, computeNoop
+	})
+	if err != nil || !swapped {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Number is a constraint for the numeric types supported by Increment and Decrement.
+type Number interface {
+	constraints.Integer | constraints.Float
+}
+
+// Increment atomically adds delta to the value of an item and returns the new value.
+// If the item does not exist, it is created with the default expiration time and delta as value.
+func Increment[K comparable, V Number](c *Cache[K, V], key K, delta V) (V, error) {
+	return c.Compute(key, func(cur V, _ bool) (V, bool) {
+		return cur + delta, true
+	})
+}
+
+// Decrement atomically subtracts delta from the value of an item and returns the new value.
+// If the item does not exist, it is created with the default expiration time and -delta as value.
+func Decrement[K comparable, V Number](c *Cache[K, V], key K, delta V) (V, error) {
+	return c.Compute(key, func(cur V, _ bool) (V, bool) {
+		return cur - delta, true
+	})
+}
@@ -0,0 +1,152 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Compute(t *testing.T) {
+	assert := assert.New(t)
+
+	var reasons []EvictReason
+
+	c := New[string, int](NoExpiration, 0)
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		reasons = append(reasons, reason)
+	})
+
+	v, err := c.Compute("a", func(old int, ok bool) (int, bool) {
+		assert.False(ok)
+		return 1, true
+	})
+	assert.NoError(err)
+	assert.Equal(1, v)
+
+	c.Set("b", 10, 20*time.Millisecond)
+	item, _ := c.Get("b")
+	exp := item.expiration
+
+	v, err = c.Compute("b", func(old int, ok bool) (int, bool) {
+		assert.True(ok)
+		return old * 2, true
+	})
+	assert.NoError(err)
+	assert.Equal(20, v)
+	item, _ = c.Get("b")
+	assert.Equal(exp, item.expiration)
+	assert.Equal([]EvictReason{EvictReplaced}, reasons)
+
+	// Returning false removes the item.
+	_, err = c.Compute("a", func(old int, ok bool) (int, bool) {
+		return 0, false
+	})
+	assert.NoError(err)
+	_, err = c.Get("a")
+	assert.Error(err)
+	assert.Equal([]EvictReason{EvictReplaced, EvictDeleted}, reasons)
+
+	// The expired items are considered nonexistent.
+	<-time.After(30 * time.Millisecond)
+	_, err = c.Compute("b", func(old int, ok bool) (int, bool) {
+		assert.False(ok)
+		assert.Equal(0, old)
+		return 1, true
+	})
+	assert.NoError(err)
+	assert.Equal([]EvictReason{EvictReplaced, EvictDeleted, EvictExpired}, reasons)
+
+	c2 := New[string, string](NoExpiration, 0)
+	_, err = c2.Compute("a", func(string, bool) (string, bool) {
+		return "", true
+	})
+	assert.Error(err)
+}
+
+func TestCache_GetAndDelete(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, int](NoExpiration, 0)
+	c.Set("a", 1, DefaultExpiration)
+	c.Set("b", 2, time.Millisecond)
+
+	item, err := c.GetAndDelete("a")
+	assert.NoError(err)
+	assert.Equal(1, item.Val())
+	assert.Equal(1, c.Count())
+
+	_, err = c.GetAndDelete("a")
+	assert.Error(err)
+
+	<-time.After(5 * time.Millisecond)
+	_, err = c.GetAndDelete("b")
+	assert.Error(err)
+	assert.Equal(0, c.Count())
+}
+
+func TestCache_CompareAndSwap(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, string](NoExpiration, 0, WithStats())
+	c.Set("a", "foo", DefaultExpiration)
+
+	var reasons []EvictReason
+	c.OnEvicted(func(_ string, _ string, reason EvictReason) {
+		reasons = append(reasons, reason)
+	})
+
+	// A failed swap leaves the cache untouched.
+	swapped, err := CompareAndSwap(c, "a", "bar", "baz")
+	assert.NoError(err)
+	assert.False(swapped)
+	assert.Empty(reasons)
+	assert.Equal(uint64(0), c.Stats().Updates)
+
+	swapped, err = CompareAndSwap(c, "a", "foo", "baz")
+	assert.NoError(err)
+	assert.True(swapped)
+	item, _ := c.Get("a")
+	assert.Equal("baz", item.Val())
+	assert.Equal([]EvictReason{EvictReplaced}, reasons)
+	assert.Equal(uint64(1), c.Stats().Updates)
+
+	swapped, err = CompareAndSwap(c, "b", "", "baz")
+	assert.NoError(err)
+	assert.False(swapped)
+	assert.Equal(1, c.Count())
+}
+
+func TestCache_IncrementDecrement(t *testing.T) {
+	assert := assert.New(t)
+
+	c1 := New[string, int](NoExpiration, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Increment(c1, "counter", 2)
+			Decrement(c1, "counter", 1)
+		}()
+	}
+	wg.Wait()
+
+	item, err := c1.Get("counter")
+	assert.NoError(err)
+	assert.Equal(100, item.Val())
+
+	c2 := New[string, float64](NoExpiration, 0)
+	v, err := Increment(c2, "a", 1.5)
+	assert.NoError(err)
+	assert.Equal(1.5, v)
+	v, err = Decrement(c2, "a", 0.5)
+	assert.NoError(err)
+	assert.Equal(1.0, v)
+
+	c2.Close()
+	_, err = Increment(c2, "a", 1)
+	assert.ErrorIs(err, ErrClosed)
+}
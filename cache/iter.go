@@ -0,0 +1,67 @@
+package cache
+
+// entries returns a copy of the keys and values of the items which are not expired.
+func (c *cache[K, V]) entries() ([]K, []V) {
+	now := c.now()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	keys := make([]K, 0, len(c.items))
+	vals := make([]V, 0, len(c.items))
+	for k, item := range c.items {
+		if !item.expired(now) {
+			keys = append(keys, k)
+			vals = append(vals, item.object)
+		}
+	}
+
+	return keys, vals
+}
+
+// Range calls fn sequentially for each item which is not expired. If fn returns false, the iteration stops.
+// The items are collected upfront, which means that fn is invoked without holding the cache lock,
+// so it can safely access the cache. The changes made during the iteration are not reflected in it.
+// Starting with Go 1.23 the Range and RangeKeys method values can be used directly in a for-range loop:
+//
+//	for key, val := range c.Range {
+//		...
+//	}
+func (c *Cache[K, V]) Range(fn func(key K, val V) bool) {
+	keys, vals := c.entries()
+	for i := range keys {
+		if !fn(keys[i], vals[i]) {
+			return
+		}
+	}
+}
+
+// Keys returns the keys of the items which are not expired, in no particular order.
+func (c *Cache[K, V]) Keys() []K {
+	keys, _ := c.entries()
+
+	return keys
+}
+
+// Snapshot returns the keys and the values of the items which are not expired.
+// The returned map is detached from the cache, which means that modifying it has no effect on the cache.
+func (c *Cache[K, V]) Snapshot() map[K]V {
+	keys, vals := c.entries()
+
+	items := make(map[K]V, len(keys))
+	for i := range keys {
+		items[keys[i]] = vals[i]
+	}
+
+	return items
+}
+
+// RangeKeys calls fn sequentially for the key of each item which is not expired. If fn returns false,
+// the iteration stops. See Range for more details.
+func (c *Cache[K, V]) RangeKeys(fn func(key K) bool) {
+	for _, k := range c.Keys() {
+		if !fn(k) {
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Iteration(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, int](NoExpiration, 0)
+	c.MapToCache(map[string]int{"a": 1, "b": 2, "c": 3}, DefaultExpiration)
+	c.Set("expired", 4, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+
+	// The expired items are excluded, even if they have not been removed yet.
+	assert.Equal(4, c.Count())
+	assert.Len(c.List(), 3)
+	assert.ElementsMatch([]string{"a", "b", "c"}, c.Keys())
+	assert.Equal(map[string]int{"a": 1, "b": 2, "c": 3}, c.Snapshot())
+
+	// Modifying the returned values does not affect the cache.
+	snapshot := c.Snapshot()
+	delete(snapshot, "a")
+	list := c.List()
+	delete(list, "b")
+	assert.Equal(4, c.Count())
+
+	// The callback can safely access the cache.
+	sum := 0
+	c.Range(func(key string, val int) bool {
+		sum += val
+		c.Delete(key)
+		return true
+	})
+	assert.Equal(6, sum)
+	assert.Equal(1, c.Count())
+
+	c.MapToCache(map[string]int{"a": 1, "b": 2, "c": 3}, DefaultExpiration)
+	visited := 0
+	c.Range(func(string, int) bool {
+		visited++
+		return visited < 2
+	})
+	assert.Equal(2, visited)
+
+	var keys []string
+	c.RangeKeys(func(key string) bool {
+		keys = append(keys, key)
+		return len(keys) < 2
+	})
+	assert.Len(keys, 2)
+}
+
+func TestCache_IterationConcurrent(t *testing.T) {
+	c := New[int, int](NoExpiration, 0)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			c.Set(i, i, DefaultExpiration)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			for range c.List() {
+			}
+			c.Range(func(int, int) bool { return true })
+		}
+	}()
+	wg.Wait()
+}
+
+func TestSharded_Iteration(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewSharded[string, int](4, NoExpiration, 0)
+	c.MapToCache(map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}, DefaultExpiration)
+
+	assert.ElementsMatch([]string{"a", "b", "c", "d"}, c.Keys())
+	assert.Equal(map[string]int{"a": 1, "b": 2, "c": 3, "d": 4}, c.Snapshot())
+
+	visited := 0
+	c.Range(func(string, int) bool {
+		visited++
+		return visited < 3
+	})
+	assert.Equal(3, visited)
+
+	var keys []string
+	c.RangeKeys(func(key string) bool {
+		keys = append(keys, key)
+		return true
+	})
+	assert.Len(keys, 4)
+}
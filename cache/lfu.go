@@ -0,0 +1,239 @@
+package cache
+
+// lfuBucket groups the values accessed the same number of times. The buckets are kept
+// into a doubly linked list ordered by their frequency, the first one having the lowest frequency.
+type lfuBucket[K comparable, V any] struct {
+	next, prev *lfuBucket[K, V]
+	freq       int
+	items      lruList[K, V]
+}
+
+// lfuEntry holds the list node of a value and the frequency bucket it belongs to.
+type lfuEntry[K comparable, V any] struct {
+	node   *node[K, V]
+	bucket *lfuBucket[K, V]
+}
+
+// LFUCache implements a fixed size LFU cache. The values are grouped into frequency buckets,
+// each of them being a doubly linked list, which makes all the operations to run in O(1) time.
+// Once the cache is full, the least recently used value of the least frequently used ones is evicted.
+type LFUCache[K comparable, V any] struct {
+	items     map[K]*lfuEntry[K, V]
+	buckets   lfuBucket[K, V] // root of the frequency buckets list. It should not be removed or changed
+	size      int
+	stats     *statsCounter
+	admission *tinyLFU[K]
+	window    *admissionWindow[K, V]
+}
+
+// NewLFU initializes a new LFU cache. The WithStats option enables collecting the cache statistics
+// and the WithTinyLFU option enables the W-TinyLFU admission policy.
+func NewLFU[K comparable, V any](size int, opts ...Option) (*LFUCache[K, V], error) {
+	if size <= 0 {
+		return nil, errInvalidSize
+	}
+
+	cfg := newConfig(opts)
+	window := newAdmissionWindow[K, V](cfg.tinyLFU, size)
+	c := &LFUCache[K, V]{
+		items:     make(map[K]*lfuEntry[K, V]),
+		size:      size - window.capacity(),
+		stats:     newStatsCounter(cfg.stats),
+		admission: newTinyLFU[K](cfg.tinyLFU, size),
+		window:    window,
+	}
+	c.buckets.next = &c.buckets
+	c.buckets.prev = &c.buckets
+
+	return c, nil
+}
+
+// Add adds a value to the cache. If the least frequently used value is evicted, this value and the key for it is returned.
+// In case the W-TinyLFU admission policy is enabled, the new value enters the admission window and the value
+// pushed out of it might not be admitted into the cache, in which case it is returned as the evicted value.
+func (c *LFUCache[K, V]) Add(key K, value V) (oldestKey K, oldestValue V, removed bool) {
+	c.admission.record(key)
+
+	if e, ok := c.items[key]; ok {
+		e.node.value = value
+		c.touch(e)
+		c.stats.update()
+		return
+	}
+	if c.window.update(key, value) {
+		c.stats.update()
+		return
+	}
+
+	// The new value enters the admission window and the value pushed out of it competes for the cache.
+	// Without an admission window, the new value itself competes for the cache.
+	key, value, ok := c.window.push(key, value)
+	if !ok {
+		return
+	}
+
+	if len(c.items) >= c.size {
+		if victim, ok := c.victim(); ok && !c.admission.admit(key, victim.key) {
+			c.stats.reject()
+			return c.window.rejected(key, value)
+		}
+		oldestKey, oldestValue, removed = c.evict()
+	}
+
+	bucket := c.buckets.next
+	if bucket.freq != 1 {
+		bucket = c.addBucket(&c.buckets, 1)
+	}
+	c.items[key] = &lfuEntry[K, V]{node: bucket.items.addFront(key, value), bucket: bucket}
+	c.stats.set()
+
+	return
+}
+
+// addBucket inserts a new frequency bucket after the provided one.
+func (c *LFUCache[K, V]) addBucket(prev *lfuBucket[K, V], freq int) *lfuBucket[K, V] {
+	bucket := &lfuBucket[K, V]{
+		prev: prev,
+		next: prev.next,
+		freq: freq,
+	}
+	bucket.items.init()
+	prev.next.prev = bucket
+	prev.next = bucket
+
+	return bucket
+}
+
+// unlink removes the entry from its frequency bucket. The bucket is dropped once it becomes empty,
+// which keeps the first bucket always holding the least frequently used values.
+func (c *LFUCache[K, V]) unlink(e *lfuEntry[K, V]) {
+	bucket := e.bucket
+	bucket.items.remove(e.node)
+	if bucket.items.len == 0 {
+		bucket.prev.next = bucket.next
+		bucket.next.prev = bucket.prev
+	}
+}
+
+// touch moves the entry into the next frequency bucket, reusing its list node.
+func (c *LFUCache[K, V]) touch(e *lfuEntry[K, V]) {
+	cur := e.bucket
+	next := cur.next
+	if next == &c.buckets || next.freq != cur.freq+1 {
+		if cur.items.len == 1 {
+			// The entry is the only one in its bucket, so the bucket itself is moved to the next frequency.
+			cur.freq++
+			return
+		}
+		next = c.addBucket(cur, cur.freq+1)
+	}
+	c.unlink(e)
+	next.items.insertFront(e.node)
+	e.bucket = next
+}
+
+// victim returns the least recently used node of the least frequently used ones.
+func (c *LFUCache[K, V]) victim() (*node[K, V], bool) {
+	if bucket := c.buckets.next; bucket != &c.buckets {
+		return bucket.items.last(), true
+	}
+	return nil, false
+}
+
+// evict removes the least recently used value of the least frequently used ones.
+func (c *LFUCache[K, V]) evict() (key K, value V, removed bool) {
+	nd, ok := c.victim()
+	if !ok {
+		return
+	}
+
+	c.unlink(c.items[nd.key])
+	delete(c.items, nd.key)
+	c.stats.evicted(EvictCapacity, 1)
+
+	return nd.key, nd.value, true
+}
+
+// Get returns the element for the key if the element is present in the cache and increments its frequency.
+func (c *LFUCache[K, V]) Get(key K) (value V, available bool) {
+	c.admission.record(key)
+	if e, ok := c.items[key]; ok {
+		c.touch(e)
+		c.stats.hit()
+		return e.node.value, true
+	}
+	if value, available = c.window.get(key); available {
+		c.stats.hit()
+		return
+	}
+	c.stats.miss()
+	return
+}
+
+// Peek returns the element for the key if the element is present in the cache, without incrementing its frequency.
+func (c *LFUCache[K, V]) Peek(key K) (value V, available bool) {
+	if e, ok := c.items[key]; ok {
+		return e.node.value, true
+	}
+	return c.window.peek(key)
+}
+
+// Contains reports whether the key is present in the cache, without incrementing its frequency.
+func (c *LFUCache[K, V]) Contains(key K) bool {
+	_, ok := c.items[key]
+	return ok || c.window.contains(key)
+}
+
+// Frequency returns the number of times the value denoted by the key has been added or accessed
+// since it entered the cache. The values held by the admission window have no frequency yet.
+func (c *LFUCache[K, V]) Frequency(key K) int {
+	if e, ok := c.items[key]; ok {
+		return e.bucket.freq
+	}
+	return 0
+}
+
+// Remove removes an element from the cache denoted by the key. The value removed is returned.
+func (c *LFUCache[K, V]) Remove(key K) (value V, removed bool) {
+	if e, ok := c.items[key]; ok {
+		c.unlink(e)
+		delete(c.items, key)
+		c.stats.evicted(EvictDeleted, 1)
+		return e.node.value, true
+	}
+	if value, removed = c.window.remove(key); removed {
+		c.stats.evicted(EvictDeleted, 1)
+	}
+	return
+}
+
+// Count returns the number of the current values from the cache.
+func (c *LFUCache[K, V]) Count() int {
+	return len(c.items) + c.window.len()
+}
+
+// Flush clears all values from the cache.
+func (c *LFUCache[K, V]) Flush() {
+	c.stats.evicted(EvictFlushed, c.Count())
+	c.items = make(map[K]*lfuEntry[K, V])
+	c.buckets.next = &c.buckets
+	c.buckets.prev = &c.buckets
+	c.window.reset()
+	c.admission.reset()
+}
+
+// Stats returns a snapshot of the cache statistics.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (c *LFUCache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (c *LFUCache[K, V]) ResetStats() {
+	c.stats.reset()
+}
+
+// AsStore returns an adapter exposing the LFU cache through the Store interface.
+func (c *LFUCache[K, V]) AsStore() Store[K, V] {
+	return boundedStore[K, V]{c}
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLFUCache_Eviction(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewLFU[string, int](3)
+	c.Add("key1", 1)
+	c.Add("key2", 2)
+	c.Add("key3", 3)
+	c.Get("key1")
+	c.Get("key1")
+	c.Get("key3")
+	assert.Equal(3, c.Frequency("key1"))
+	assert.Equal(1, c.Frequency("key2"))
+	assert.Equal(0, c.Frequency("key4"))
+
+	// The least frequently used value is evicted.
+	key, val, removed := c.Add("key4", 4)
+	assert.True(removed)
+	assert.Equal("key2", key)
+	assert.Equal(2, val)
+
+	// Between the values with the same frequency the least recently used one is evicted.
+	c.Get("key4")
+	key, _, removed = c.Add("key5", 5)
+	assert.True(removed)
+	assert.Equal("key3", key)
+
+	// The least frequently used values are still found after removing the only value of the lowest frequency.
+	c.Remove("key5")
+	c.Add("key6", 6)
+	c.Remove("key6")
+	assert.Equal(2, c.Count())
+	c.Add("key7", 7)
+	key, _, removed = c.Add("key8", 8)
+	assert.True(removed)
+	assert.Equal("key7", key)
+	assert.Equal(3, c.Count())
+
+	// The frequency buckets are kept ordered when the values are accessed.
+	c.Flush()
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("c", 3)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Get("b")
+	c.Get("c")
+	assert.Equal(3, c.Frequency("a"))
+	assert.Equal(3, c.Frequency("b"))
+	assert.Equal(2, c.Frequency("c"))
+	var freqs []int
+	for b := c.buckets.next; b != &c.buckets; b = b.next {
+		freqs = append(freqs, b.freq)
+	}
+	assert.Equal([]int{2, 3}, freqs)
+
+	c.Remove("c")
+	key, _, removed = c.Add("d", 4)
+	assert.False(removed)
+	key, _, removed = c.Add("e", 5)
+	assert.True(removed)
+	assert.Equal("d", key)
+}
@@ -0,0 +1,86 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/esimov/gogu/internal/keys"
+)
+
+// GetOrLoad returns the value of the cache item defined by its key. In case the item does not exist or
+// it is expired, the loader function is invoked and its result is stored in the cache with the returned duration.
+// Concurrent calls for the same key are deduplicated, which means that only one loader invocation
+// is in flight for a given key at a time, the other callers waiting for its result.
+// The loader errors are returned to the callers, without being stored in the cache.
+//
+// If the cache was created with the WithStaleWhileRevalidate option, an expired item which is still
+// within its stale period is returned immediately, while the value is refreshed in the background.
+// The errors of the background refresh are reported to the function set by WithRefreshErrorHandler.
+func (c *Cache[K, V]) GetOrLoad(key K, fn func(K) (V, time.Duration, error)) (V, error) {
+	var v V
+
+	now := c.now()
+
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return v, ErrClosed
+	}
+	item, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if ok {
+		if !item.expired(now) {
+			c.stats.hit()
+			c.accessed(key)
+			return item.object, nil
+		}
+		if c.staleTTL > 0 && !item.expired(now-int64(c.staleTTL)) {
+			c.stats.hit()
+			ch := c.group.DoChan(keys.String(key), c.loader(key, fn))
+			if c.refreshErr != nil {
+				go func() {
+					if res := <-ch; res.Err != nil {
+						c.refreshErr(key, res.Err)
+					}
+				}()
+			}
+			return item.object, nil
+		}
+	}
+	c.stats.miss()
+
+	val, err, _ := c.group.Do(keys.String(key), c.loader(key, fn))
+	if err != nil {
+		return v, err
+	}
+	v, _ = val.(V)
+
+	return v, nil
+}
+
+// loader wraps the loader function into a singleflight compatible function,
+// which stores the loaded value in the cache.
+func (c *Cache[K, V]) loader(key K, fn func(K) (V, time.Duration, error)) func() (any, error) {
+	return func() (any, error) {
+		// The item might have been loaded in the meantime by a call which completed
+		// just before the current one has been started.
+		c.mu.RLock()
+		item, ok := c.items[key]
+		c.mu.RUnlock()
+		if ok && !item.expired(c.now()) {
+			return item.object, nil
+		}
+
+		start := c.clock.Now()
+		val, d, err := fn(key)
+		c.stats.load(c.clock.Since(start), err)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.add(key, val, d); err != nil {
+			return nil, err
+		}
+
+		return val, nil
+	}
+}
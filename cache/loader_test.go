@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_GetOrLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int32
+	c := New[string, int](NoExpiration, 0)
+
+	loader := func(key string) (int, time.Duration, error) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(20 * time.Millisecond)
+		return len(key), DefaultExpiration, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.GetOrLoad("foo", loader)
+			assert.NoError(err)
+			assert.Equal(3, v)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	// The loaded value should be served from the cache.
+	v, err := c.GetOrLoad("foo", loader)
+	assert.NoError(err)
+	assert.Equal(3, v)
+	assert.Equal(int32(1), atomic.LoadInt32(&calls))
+
+	item, err := c.Get("foo")
+	assert.NoError(err)
+	assert.Equal(3, item.Val())
+
+	// The loader errors are not cached.
+	errLoad := errors.New("load failed")
+	v, err = c.GetOrLoad("bar", func(string) (int, time.Duration, error) {
+		return 0, DefaultExpiration, errLoad
+	})
+	assert.ErrorIs(err, errLoad)
+	assert.Equal(0, v)
+	_, err = c.Get("bar")
+	assert.Error(err)
+
+	// The expired items are reloaded.
+	c.Set("baz", 1, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+	v, err = c.GetOrLoad("baz", func(string) (int, time.Duration, error) {
+		return 2, DefaultExpiration, nil
+	})
+	assert.NoError(err)
+	assert.Equal(2, v)
+
+	c.Close()
+	_, err = c.GetOrLoad("foo", loader)
+	assert.ErrorIs(err, ErrClosed)
+}
+
+func TestCache_GetOrLoadInterfaceKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[any, string](NoExpiration, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan string)
+	go func() {
+		val, err := c.GetOrLoad(1, func(any) (string, time.Duration, error) {
+			close(started)
+			<-release
+			return "int", DefaultExpiration, nil
+		})
+		assert.NoError(err)
+		done <- val
+	}()
+	<-started
+
+	// The key int64(1) differs from int(1), so its load is not deduplicated with the one in flight.
+	val, err := c.GetOrLoad(int64(1), func(any) (string, time.Duration, error) {
+		return "int64", DefaultExpiration, nil
+	})
+	assert.NoError(err)
+	assert.Equal("int64", val)
+
+	close(release)
+	assert.Equal("int", <-done)
+
+	item, err := c.Get(int64(1))
+	assert.NoError(err)
+	assert.Equal("int64", item.Val())
+	item, err = c.Get(1)
+	assert.NoError(err)
+	assert.Equal("int", item.Val())
+}
+
+func TestCache_GetOrLoadStale(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, int](NoExpiration, 0, WithStaleWhileRevalidate(time.Second))
+	c.Set("foo", 1, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+
+	refreshed := make(chan struct{})
+	loader := func(string) (int, time.Duration, error) {
+		defer close(refreshed)
+		return 2, time.Minute, nil
+	}
+
+	// The stale value is returned immediately, while the refresh runs in the background.
+	v, err := c.GetOrLoad("foo", loader)
+	assert.NoError(err)
+	assert.Equal(1, v)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("the stale item was not refreshed")
+	}
+	assert.Eventually(func() bool {
+		item, err := c.Get("foo")
+		return err == nil && item.Val() == 2
+	}, time.Second, time.Millisecond)
+
+	// The items within their stale period are not removed by the cleanup.
+	c.Set("bar", 1, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+	c.DeleteExpired()
+	assert.Equal(2, c.Count())
+}
+
+func TestCache_GetOrLoadStaleError(t *testing.T) {
+	assert := assert.New(t)
+
+	failed := make(chan error, 1)
+	c := New[string, int](NoExpiration, 0,
+		WithStats(),
+		WithStaleWhileRevalidate(time.Second),
+		WithRefreshErrorHandler(func(key string, err error) {
+			assert.Equal("foo", key)
+			failed <- err
+		}),
+	)
+	c.Set("foo", 1, time.Millisecond)
+	<-time.After(5 * time.Millisecond)
+
+	errLoad := errors.New("load failed")
+	v, err := c.GetOrLoad("foo", func(string) (int, time.Duration, error) {
+		return 0, 0, errLoad
+	})
+	assert.NoError(err)
+	assert.Equal(1, v)
+
+	select {
+	case err := <-failed:
+		assert.ErrorIs(err, errLoad)
+	case <-time.After(time.Second):
+		t.Fatal("the refresh error was not reported")
+	}
+	assert.Equal(uint64(1), c.Stats().LoadErrors)
+
+	assert.Panics(func() {
+		New[int, int](NoExpiration, 0, WithRefreshErrorHandler(func(string, error) {}))
+	})
+}
@@ -23,12 +23,16 @@ type lruList[K comparable, V any] struct {
 
 // newLRUList initializes a new double linked list with the root element with an initial size of 0.
 func newLRUList[K comparable, V any]() *lruList[K, V] {
-	// initialize the root element
-	lst := lruList[K, V]{root: node[K, V]{}, len: 0}
-	lst.root.prev = &lst.root
-	lst.root.next = &lst.root
+	return new(lruList[K, V]).init()
+}
+
+// init initializes the root element of the list, which makes it possible to embed the list into other types.
+func (l *lruList[K, V]) init() *lruList[K, V] {
+	l.root.prev = &l.root
+	l.root.next = &l.root
+	l.len = 0
 
-	return &lst
+	return l
 }
 
 // moveAfter moves nd node after the current node.
@@ -77,6 +81,16 @@ func (l *lruList[K, V]) addFront(key K, value V) *node[K, V] {
 	return x
 }
 
+// insertFront inserts a node removed from a list to the front of the list, without allocating a new node.
+func (l *lruList[K, V]) insertFront(nd *node[K, V]) {
+	nd.prev = &l.root
+	nd.next = l.root.next
+	nd.list = l
+	l.root.next.prev = nd
+	l.root.next = nd
+	l.len++
+}
+
 // last returns the last node from the list.
 func (l *lruList[K, V]) last() *node[K, V] {
 	return l.root.prev
@@ -111,46 +125,88 @@ type LRUCache[K comparable, V any] struct {
 	items     map[K]*node[K, V]
 	evictList *lruList[K, V]
 	size      int
+	stats     *statsCounter
+	admission *tinyLFU[K]
+	window    *admissionWindow[K, V]
 }
 
-// NewLRU initializes a new LRU cache.
-func NewLRU[K comparable, V any](size int) (*LRUCache[K, V], error) {
+var _ StatsProvider = (*LRUCache[string, any])(nil)
+
+var errInvalidSize = errors.New("size must be a positive value")
+
+// NewLRU initializes a new LRU cache. The WithStats option enables collecting the cache statistics
+// and the WithTinyLFU option enables the W-TinyLFU admission policy.
+func NewLRU[K comparable, V any](size int, opts ...Option) (*LRUCache[K, V], error) {
 	if size <= 0 {
-		return nil, errors.New("size must be a positive value")
+		return nil, errInvalidSize
 	}
 
+	cfg := newConfig(opts)
+	window := newAdmissionWindow[K, V](cfg.tinyLFU, size)
 	lru := &LRUCache[K, V]{
 		items:     make(map[K]*node[K, V]),
 		evictList: newLRUList[K, V](),
-		size:      size,
+		size:      size - window.capacity(),
+		stats:     newStatsCounter(cfg.stats),
+		admission: newTinyLFU[K](cfg.tinyLFU, size),
+		window:    window,
 	}
 
 	return lru, nil
 }
 
 // Add adds a value to the cache. If the oldest value is evicted, this value and they key for it is returned.
+// In case the W-TinyLFU admission policy is enabled, the new value enters the admission window and the value
+// pushed out of it might not be admitted into the cache, in which case it is returned as the evicted value.
 func (c *LRUCache[K, V]) Add(key K, value V) (oldestKey K, oldestValue V, removed bool) {
+	c.admission.record(key)
+
 	// If the element is in the cache, move it to the front and return
 	if item, ok := c.items[key]; ok {
 		c.evictList.moveFront(item)
 		item.value = value
+		c.stats.update()
+		return
+	}
+	if c.window.update(key, value) {
+		c.stats.update()
+		return
+	}
+
+	// The new element enters the admission window and the element pushed out of it competes for the cache.
+	// Without an admission window, the new element itself competes for the cache.
+	key, value, ok := c.window.push(key, value)
+	if !ok {
 		return
 	}
 
+	// The new element should be more frequently used than the element evicted for it.
+	if c.evictList.len >= c.size {
+		if oldest := c.evictList.last(); !c.admission.admit(key, oldest.key) {
+			c.stats.reject()
+			return c.window.rejected(key, value)
+		}
+	}
+
 	// Since this is a new element, put this to the front
 	item := c.evictList.addFront(key, value)
 	c.items[key] = item
+	c.stats.set()
 
 	// Remove the oldest element if the cache is full
-	if c.Count() > c.size {
-		return c.RemoveOldest()
+	if c.evictList.len > c.size {
+		if item := c.evictList.last(); item != &c.evictList.root {
+			delete(c.items, item.key)
+			c.stats.evicted(EvictCapacity, 1)
+			return item.key, item.value, c.evictList.removeLast()
+		}
 	}
 	return
 }
 
 // Count return the number of the current values from the cache. It should be LE then the initial size of the cache.
 func (c *LRUCache[K, V]) Count() int {
-	return c.evictList.len
+	return c.evictList.len + c.window.len()
 }
 
 // GetOldest returns the oldest key/value pair from the cache if the cache has any values.
@@ -160,21 +216,35 @@ func (c *LRUCache[K, V]) GetOldest() (key K, value V, available bool) {
 		c.evictList.moveFront(item)
 		return item.key, item.value, true
 	}
+	if key, available = c.window.oldest(); available {
+		value, _ = c.window.get(key)
+	}
 	return
 }
 
 // Get return the element for the key if the element is present in the cache.
 func (c *LRUCache[K, V]) Get(key K) (value V, available bool) {
+	c.admission.record(key)
 	if item, ok := c.items[key]; ok {
 		// The item was touched, move it to the front in the list
 		c.evictList.moveFront(item)
+		c.stats.hit()
 		return item.value, true
 	}
+	if value, available = c.window.get(key); available {
+		c.stats.hit()
+		return
+	}
+	c.stats.miss()
 	return
 }
 
 // GetYoungest returns the youngest key/value pair from the cache if the cache has any values.
 func (c *LRUCache[K, V]) GetYoungest() (key K, value V, available bool) {
+	if key, available = c.window.youngest(); available {
+		value, _ = c.window.peek(key)
+		return
+	}
 	if item := c.evictList.first(); item != &c.evictList.root {
 		return item.key, item.value, true
 	}
@@ -185,8 +255,12 @@ func (c *LRUCache[K, V]) GetYoungest() (key K, value V, available bool) {
 func (c *LRUCache[K, V]) RemoveOldest() (key K, value V, removed bool) {
 	if item := c.evictList.last(); item != &c.evictList.root {
 		delete(c.items, item.key)
+		c.stats.evicted(EvictDeleted, 1)
 		return item.key, item.value, c.evictList.removeLast()
 	}
+	if key, value, removed = c.window.removeOldest(); removed {
+		c.stats.evicted(EvictDeleted, 1)
+	}
 	return
 }
 
@@ -196,22 +270,105 @@ func (c *LRUCache[K, V]) Remove(key K) (value V, removed bool) {
 		// The item was touched, move it to the front in the list
 		delete(c.items, item.key)
 		c.evictList.remove(item)
+		c.stats.evicted(EvictDeleted, 1)
 		return item.value, true
 	}
+	if value, removed = c.window.remove(key); removed {
+		c.stats.evicted(EvictDeleted, 1)
+	}
 	return
 }
 
 // RemoveYoungest removes the youngest value from the cache. The key/value pair removed is returned.
 func (c *LRUCache[K, V]) RemoveYoungest() (key K, value V, removed bool) {
+	if key, available := c.window.youngest(); available {
+		value, removed = c.window.remove(key)
+		c.stats.evicted(EvictDeleted, 1)
+		return key, value, removed
+	}
 	if item := c.evictList.first(); item != &c.evictList.root {
 		delete(c.items, item.key)
-		return item.key, item.value, c.evictList.removeLast()
+		c.stats.evicted(EvictDeleted, 1)
+		return item.key, item.value, c.evictList.remove(item)
 	}
 	return
 }
 
+// Peek returns the element for the key if the element is present in the cache,
+// without marking it as recently used.
+func (c *LRUCache[K, V]) Peek(key K) (value V, available bool) {
+	if item, ok := c.items[key]; ok {
+		return item.value, true
+	}
+	return c.window.peek(key)
+}
+
+// Contains reports whether the key is present in the cache, without marking it as recently used.
+func (c *LRUCache[K, V]) Contains(key K) bool {
+	_, ok := c.items[key]
+	return ok || c.window.contains(key)
+}
+
+// Resize changes the capacity of the cache. In case the new capacity is smaller
+// than the number of the values from the cache, the oldest values are evicted.
+// It returns the number of the evicted values.
+func (c *LRUCache[K, V]) Resize(size int) (int, error) {
+	var evicted int
+
+	err := c.resize(size, func(K, V) { evicted++ })
+	return evicted, err
+}
+
+// resize changes the capacity of the cache and invokes fn for each evicted value.
+func (c *LRUCache[K, V]) resize(size int, fn func(K, V)) error {
+	if size <= 0 {
+		return errInvalidSize
+	}
+
+	c.window.resize(size, func(key K, value V) {
+		c.stats.evicted(EvictCapacity, 1)
+		fn(key, value)
+	})
+	c.size = size - c.window.capacity()
+	for c.evictList.len > c.size {
+		item := c.evictList.last()
+		delete(c.items, item.key)
+		c.evictList.remove(item)
+		c.stats.evicted(EvictCapacity, 1)
+		fn(item.key, item.value)
+	}
+	return nil
+}
+
+// each invokes fn for each key/value pair from the cache, including the ones held by the admission window.
+// The current pair can be removed by fn.
+func (c *LRUCache[K, V]) each(fn func(K, V)) {
+	for key, item := range c.items {
+		fn(key, item.value)
+	}
+	if c.window != nil {
+		for key, nd := range c.window.lru.nodes {
+			fn(key, nd.value)
+		}
+	}
+}
+
 // Flush clears all values from the cache.
 func (c *LRUCache[K, V]) Flush() {
+	c.stats.evicted(EvictFlushed, c.Count())
 	c.items = make(map[K]*node[K, V])
 	c.evictList = newLRUList[K, V]()
+	c.window.reset()
+	c.admission.reset()
+}
+
+// Stats returns a snapshot of the cache statistics.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (c *LRUCache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (c *LRUCache[K, V]) ResetStats() {
+	c.stats.reset()
 }
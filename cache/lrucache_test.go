@@ -154,6 +154,78 @@ func TestLRUCache_Remove(t *testing.T) {
 	assert.Equal(0, lruCache.Count())
 }
 
+func TestLRUCache_PeekContainsResize(t *testing.T) {
+	assert := assert.New(t)
+
+	lruCache, _ := NewLRU[string, int](3)
+	lruCache.Add("key1", 1)
+	lruCache.Add("key2", 2)
+	lruCache.Add("key3", 3)
+
+	val, ok := lruCache.Peek("key1")
+	assert.True(ok)
+	assert.Equal(1, val)
+	assert.True(lruCache.Contains("key1"))
+	assert.False(lruCache.Contains("key4"))
+
+	// Peek does not promote the key, so it is still the oldest one.
+	key, _, _ := lruCache.GetOldest()
+	assert.Equal("key1", key)
+
+	_, err := lruCache.Resize(0)
+	assert.Error(err)
+
+	evicted, err := lruCache.Resize(1)
+	assert.NoError(err)
+	assert.Equal(2, evicted)
+	assert.Equal(1, lruCache.Count())
+	assert.True(lruCache.Contains("key1"))
+
+	// The youngest value is removed from both the list and the map.
+	_, err = lruCache.Resize(2)
+	assert.NoError(err)
+	lruCache.Add("key2", 2)
+	key, _, removed := lruCache.RemoveYoungest()
+	assert.True(removed)
+	assert.Equal("key2", key)
+	assert.False(lruCache.Contains("key2"))
+	assert.True(lruCache.Contains("key1"))
+}
+
+func TestLRUCache_AdmissionWindow(t *testing.T) {
+	assert := assert.New(t)
+
+	lruCache, _ := NewLRU[string, int](3, WithTinyLFU())
+	lruCache.Add("key1", 1)
+	lruCache.Add("key2", 2)
+	lruCache.Add("key3", 3)
+	assert.Equal(3, lruCache.Count())
+
+	// The youngest value is held by the admission window, while the oldest one by the main cache.
+	key, _, _ := lruCache.GetYoungest()
+	assert.Equal("key3", key)
+	key, _, _ = lruCache.GetOldest()
+	assert.Equal("key1", key)
+
+	// Shrinking the cache evicts the values from both the admission window and the main cache.
+	evicted, err := lruCache.Resize(2)
+	assert.NoError(err)
+	assert.Equal(1, evicted)
+	assert.Equal(2, lruCache.Count())
+
+	// A cache holding a single value has no room for the admission window.
+	evicted, err = lruCache.Resize(1)
+	assert.NoError(err)
+	assert.Equal(1, evicted)
+	assert.False(lruCache.Contains("key3"))
+
+	key, _, removed := lruCache.RemoveYoungest()
+	assert.True(removed)
+	// GetOldest has promoted "key1", so "key2" was evicted first.
+	assert.Equal("key1", key)
+	assert.Equal(0, lruCache.Count())
+}
+
 func TestLRUCache_GeneralUsage(t *testing.T) {
 	assert := assert.New(t)
 
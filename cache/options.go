@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/esimov/gogu/clock"
+)
+
+// config holds the optional settings of a cache instance.
+type config struct {
+	staleTTL   time.Duration
+	refreshErr any
+	stats      bool
+	maxEntries int
+	maxCost    int64
+	cost       any
+	policy     EvictionPolicy
+	sliding    bool
+	tinyLFU    bool
+	clock      clock.Clock
+}
+
+// Option customizes the cache behavior on initialization.
+type Option func(*config)
+
+// WithStaleWhileRevalidate enables the soft expiration mode, used by the GetOrLoad method.
+// The expired items are kept in the cache for an extra stale period, during which GetOrLoad
+// returns the stale value immediately and refreshes it in the background.
+// Once the stale period elapses, the item is removed from the cache on the next cleanup.
+func WithStaleWhileRevalidate(stale time.Duration) Option {
+	return func(cfg *config) {
+		cfg.staleTTL = stale
+	}
+}
+
+// WithRefreshErrorHandler sets the function invoked with the error of a failed background refresh.
+// The stale values are refreshed in the background by GetOrLoad when the WithStaleWhileRevalidate
+// option is used, which means that their errors can't be returned to the callers. The failed refreshes
+// are also counted as LoadErrors in the statistics. The key type of the function should match
+// the key type of the cache, otherwise New panics.
+func WithRefreshErrorHandler[K comparable](fn func(key K, err error)) Option {
+	return func(cfg *config) {
+		cfg.refreshErr = fn
+	}
+}
+
+// WithStats enables collecting the cache statistics, which can be retrieved with the Stats method.
+// Collecting the statistics is disabled by default.
+func WithStats() Option {
+	return func(cfg *config) {
+		cfg.stats = true
+	}
+}
+
+// WithMaxEntries limits the number of items stored in the cache.
+// Once the limit is exceeded, the items are evicted according to the eviction policy.
+func WithMaxEntries(n int) Option {
+	return func(cfg *config) {
+		cfg.maxEntries = n
+	}
+}
+
+// WithMaxCost limits the total cost of the items stored in the cache.
+// The cost of an item is computed with the function provided by the WithCost option,
+// otherwise each item has a cost of 1. Once the limit is exceeded, the items are
+// evicted according to the eviction policy.
+func WithMaxCost(max int64) Option {
+	return func(cfg *config) {
+		cfg.maxCost = max
+	}
+}
+
+// WithCost sets the function used for computing the cost of an item, like its size in bytes.
+// The value type of the function should match the value type of the cache, otherwise New panics.
+func WithCost[V any](fn func(V) int64) Option {
+	return func(cfg *config) {
+		cfg.cost = fn
+	}
+}
+
+// WithEvictionPolicy sets the policy used for evicting the items of a bounded cache.
+// The default policy is PolicyNearestExpiration.
+func WithEvictionPolicy(p EvictionPolicy) Option {
+	return func(cfg *config) {
+		cfg.policy = p
+	}
+}
+
+// WithSlidingExpiration enables the sliding expiration mode, in which every successful
+// read renews the item expiration time with its original lifetime. This means that an item
+// expires only after it hasn't been accessed for the duration it was stored with.
+// Renewing the expiration time requires the write lock of the cache, which means that in this
+// mode the reads contend with each other the same way the writes do, instead of running in parallel.
+// Under read heavy concurrent workloads consider spreading the items across a Sharded cache.
+func WithSlidingExpiration() Option {
+	return func(cfg *config) {
+		cfg.sliding = true
+	}
+}
+
+// WithTinyLFU enables the W-TinyLFU admission policy on the fixed size caches (LRUCache, LFUCache,
+// TwoQueueCache, ARCCache, S3FIFOCache and SyncLRU). The new values enter a small LRU admission window
+// holding 1% of the cache size, and once the cache is full, a value pushed out of the window is admitted
+// into the main cache only if it's estimated to be more frequently used than the value which would be evicted for it.
+// The access frequency of the keys is estimated with a count-min sketch, which is periodically aged,
+// fronted by a doorkeeper bloom filter recording the keys accessed only once.
+// This keeps the values used only once from polluting the cache.
+func WithTinyLFU() Option {
+	return func(cfg *config) {
+		cfg.tinyLFU = true
+	}
+}
+
+// WithClock sets the clock used for computing the expiration time of the items and for scheduling
+// the cleanup of the expired items. The real clock is used by default, while a fake clock
+// (see clock.NewFake) makes it possible to test the expiration without waiting for it.
+func WithClock(clk clock.Clock) Option {
+	return func(cfg *config) {
+		cfg.clock = clk
+	}
+}
+
+// newConfig applies the options on top of the default settings.
+func newConfig(opts []Option) config {
+	var cfg config
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.clock == nil {
+		cfg.clock = clock.New()
+	}
+
+	return cfg
+}
@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// Encoder writes the encoded representation of a value into the underlying stream.
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder reads and decodes a value from the underlying stream.
+type Decoder interface {
+	Decode(v any) error
+}
+
+// Codec defines the serialization format used for saving and loading the cache items.
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+type gobCodec struct{}
+
+func (gobCodec) NewEncoder(w io.Writer) Encoder { return gob.NewEncoder(w) }
+func (gobCodec) NewDecoder(r io.Reader) Decoder { return gob.NewDecoder(r) }
+
+type jsonCodec struct{}
+
+func (jsonCodec) NewEncoder(w io.Writer) Encoder { return json.NewEncoder(w) }
+func (jsonCodec) NewDecoder(r io.Reader) Decoder { return json.NewDecoder(r) }
+
+var (
+	// GobCodec serializes the cache items using the encoding/gob package. This is the default codec.
+	// Values stored as interfaces should be registered upfront with gob.Register.
+	GobCodec Codec = gobCodec{}
+	// JSONCodec serializes the cache items using the encoding/json package.
+	JSONCodec Codec = jsonCodec{}
+)
+
+// snapshotItem is the serializable representation of a cache item.
+type snapshotItem[K comparable, V any] struct {
+	Key        K
+	Value      V
+	Expiration int64
+	TTL        time.Duration
+	Tags       []string
+}
+
+// snapshotConfig holds the settings applied on saving and loading the cache items.
+type snapshotConfig struct {
+	codec     Codec
+	overwrite bool
+}
+
+// SnapshotOption customizes the behavior of the Save and Load methods.
+type SnapshotOption func(*snapshotConfig)
+
+// WithCodec sets the codec used for serializing the cache items. The default one is GobCodec.
+func WithCodec(codec Codec) SnapshotOption {
+	return func(cfg *snapshotConfig) {
+		cfg.codec = codec
+	}
+}
+
+// WithOverwrite instructs the Load method to replace the existing unexpired items with the loaded ones.
+// By default these items are kept untouched.
+func WithOverwrite() SnapshotOption {
+	return func(cfg *snapshotConfig) {
+		cfg.overwrite = true
+	}
+}
+
+func newSnapshotConfig(opts []SnapshotOption) *snapshotConfig {
+	cfg := &snapshotConfig{codec: GobCodec}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg
+}
+
+// Save writes the cache items which are not expired into w, together with their expiration time.
+func (c *Cache[K, V]) Save(w io.Writer, opts ...SnapshotOption) error {
+	cfg := newSnapshotConfig(opts)
+	now := c.now()
+
+	c.mu.RLock()
+	if c.closed {
+		c.mu.RUnlock()
+		return ErrClosed
+	}
+	items := make([]snapshotItem[K, V], 0, len(c.items))
+	for k, item := range c.items {
+		if item.expired(now) {
+			continue
+		}
+		items = append(items, snapshotItem[K, V]{k, item.object, item.expiration, item.ttl, item.tags})
+	}
+	c.mu.RUnlock()
+
+	return cfg.codec.NewEncoder(w).Encode(items)
+}
+
+// Load reads the cache items previously written with Save from r.
+// The items expired in the meantime are skipped. The existing unexpired items are
+// not overwritten, unless the WithOverwrite option is provided. The items which can't be stored
+// in the cache (e.g. their cost exceeds the limit set by WithMaxCost) are skipped as well,
+// while their errors are joined into the returned error.
+func (c *Cache[K, V]) Load(r io.Reader, opts ...SnapshotOption) error {
+	var (
+		items []snapshotItem[K, V]
+		errs  error
+	)
+
+	cfg := newSnapshotConfig(opts)
+	if err := cfg.codec.NewDecoder(r).Decode(&items); err != nil {
+		return err
+	}
+
+	var evicted []eviction[K, V]
+	now := c.now()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	for _, it := range items {
+		item := &Item[V]{
+			object:     it.Value,
+			expiration: it.Expiration,
+			ttl:        it.TTL,
+			tags:       it.Tags,
+		}
+		if item.expired(now) {
+			continue
+		}
+		cost, err := c.validate(it.Key, it.Value)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		item.cost = cost
+		if old, ok := c.items[it.Key]; ok {
+			if !old.expired(now) {
+				if !cfg.overwrite {
+					continue
+				}
+				evicted = append(evicted, eviction[K, V]{it.Key, old.object, EvictReplaced})
+			} else {
+				evicted = append(evicted, eviction[K, V]{it.Key, old.object, EvictExpired})
+			}
+		}
+		c.store(it.Key, item)
+	}
+	evicted = append(evicted, c.evictOverflow()...)
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return errs
+}
+
+// SaveFile saves the cache items into the file denoted by path. The file is created if it does not exist.
+func (c *Cache[K, V]) SaveFile(path string, opts ...SnapshotOption) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	if err := c.Save(w, opts...); err != nil {
+		f.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// LoadFile loads the cache items from the file denoted by path.
+func (c *Cache[K, V]) LoadFile(path string, opts ...SnapshotOption) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return c.Load(bufio.NewReader(f), opts...)
+}
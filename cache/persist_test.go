@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SaveLoad(t *testing.T) {
+	assert := assert.New(t)
+
+	for _, codec := range []Codec{GobCodec, JSONCodec} {
+		var buf bytes.Buffer
+
+		c1 := New[string, SampleStruct](NoExpiration, 0)
+		c1.Set("foo", SampleStruct{Id: 1}, DefaultExpiration)
+		c1.Set("bar", SampleStruct{Id: 2, Items: []*SampleStruct{{Id: 3}}}, time.Minute)
+		c1.Set("baz", SampleStruct{Id: 4}, 5*time.Millisecond)
+
+		err := c1.Save(&buf, WithCodec(codec))
+		assert.NoError(err)
+
+		<-time.After(10 * time.Millisecond)
+
+		c2 := New[string, SampleStruct](NoExpiration, 0)
+		c2.Set("foo", SampleStruct{Id: 10}, DefaultExpiration)
+		err = c2.Load(bytes.NewReader(buf.Bytes()), WithCodec(codec))
+		assert.NoError(err)
+
+		// The item expired in the meantime should be skipped.
+		assert.Equal(2, c2.Count())
+		_, err = c2.Get("baz")
+		assert.Error(err)
+
+		// The existing unexpired items are not overwritten by default.
+		item, _ := c2.Get("foo")
+		assert.Equal(10, item.Val().Id)
+
+		item, _ = c2.Get("bar")
+		assert.Equal(2, item.Val().Id)
+		assert.Equal(3, item.Val().Items[0].Id)
+		orig, _ := c1.Get("bar")
+		assert.Equal(orig.expiration, item.expiration)
+
+		err = c2.Load(bytes.NewReader(buf.Bytes()), WithCodec(codec), WithOverwrite())
+		assert.NoError(err)
+		item, _ = c2.Get("foo")
+		assert.Equal(1, item.Val().Id)
+	}
+}
+
+func TestCache_LoadMaxCost(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+
+	c1 := New[string, string](NoExpiration, 0)
+	c1.Set("foo", "aa", DefaultExpiration)
+	c1.Set("bar", "bbbbbb", DefaultExpiration)
+	assert.NoError(c1.Save(&buf))
+
+	c2 := New[string, string](NoExpiration, 0,
+		WithMaxCost(5),
+		WithCost(func(v string) int64 { return int64(len(v)) }),
+	)
+	// The items exceeding the maximum cost are skipped.
+	err := c2.Load(&buf)
+	assert.Error(err)
+	assert.Equal(1, c2.Count())
+	assert.Equal(int64(2), c2.cost)
+	_, err = c2.Get("bar")
+	assert.Error(err)
+}
+
+func TestCache_SaveLoadFile(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "cache.gob")
+
+	c1 := New[string, int](NoExpiration, 0)
+	c1.MapToCache(map[string]int{"a": 1, "b": 2}, DefaultExpiration)
+	err := c1.SaveFile(path)
+	assert.NoError(err)
+
+	c2 := New[string, int](NoExpiration, 0)
+	err = c2.LoadFile(path)
+	assert.NoError(err)
+	assert.Equal(2, c2.Count())
+	item, _ := c2.Get("b")
+	assert.Equal(2, item.Val())
+
+	err = c2.LoadFile(filepath.Join(t.TempDir(), "missing.gob"))
+	assert.Error(err)
+
+	err = c2.Load(bytes.NewReader([]byte("invalid")), WithCodec(JSONCodec))
+	assert.Error(err)
+
+	c2.Close()
+	assert.ErrorIs(c2.SaveFile(path), ErrClosed)
+}
@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"container/heap"
+	"math"
+)
+
+// EvictionPolicy defines which item is removed first once a bounded cache reaches its limits.
+type EvictionPolicy int
+
+const (
+	// PolicyNearestExpiration evicts the item which is the closest to its expiration time.
+	// The items which never expire are evicted last.
+	PolicyNearestExpiration EvictionPolicy = iota
+	// PolicyLRU evicts the least recently used item.
+	PolicyLRU
+)
+
+// evictPolicy keeps track of the order in which the items of a bounded cache should be evicted.
+// The methods are invoked while holding the cache lock.
+type evictPolicy[K comparable] interface {
+	// add registers a new item or refreshes an existing one.
+	add(key K, exp int64)
+	// access marks the item as being recently used.
+	access(key K)
+	// remove drops the item from the eviction order.
+	remove(key K)
+	// victim returns the key of the item which should be evicted next.
+	victim() (K, bool)
+	// reset drops all the items.
+	reset()
+}
+
+// newEvictPolicy returns the implementation of the eviction policy.
+func newEvictPolicy[K comparable](p EvictionPolicy) evictPolicy[K] {
+	if p == PolicyLRU {
+		return newLRUPolicy[K]()
+	}
+	return newExpPolicy[K]()
+}
+
+// lruPolicy orders the items by their last access using a doubly linked list.
+type lruPolicy[K comparable] struct {
+	list  *lruList[K, struct{}]
+	nodes map[K]*node[K, struct{}]
+}
+
+func newLRUPolicy[K comparable]() *lruPolicy[K] {
+	return &lruPolicy[K]{
+		list:  newLRUList[K, struct{}](),
+		nodes: make(map[K]*node[K, struct{}]),
+	}
+}
+
+func (p *lruPolicy[K]) add(key K, _ int64) {
+	if nd, ok := p.nodes[key]; ok {
+		p.list.moveFront(nd)
+		return
+	}
+	p.nodes[key] = p.list.addFront(key, struct{}{})
+}
+
+func (p *lruPolicy[K]) access(key K) {
+	if nd, ok := p.nodes[key]; ok {
+		p.list.moveFront(nd)
+	}
+}
+
+func (p *lruPolicy[K]) remove(key K) {
+	if nd, ok := p.nodes[key]; ok {
+		p.list.remove(nd)
+		delete(p.nodes, key)
+	}
+}
+
+func (p *lruPolicy[K]) victim() (key K, ok bool) {
+	if nd := p.list.last(); nd != &p.list.root {
+		return nd.key, true
+	}
+	return
+}
+
+func (p *lruPolicy[K]) reset() {
+	p.list = newLRUList[K, struct{}]()
+	p.nodes = make(map[K]*node[K, struct{}])
+}
+
+// expEntry is an element of the expiration queue.
+type expEntry[K comparable] struct {
+	key   K
+	exp   int64
+	index int
+}
+
+// expQueue is a min-heap of entries ordered by their expiration time. It implements heap.Interface.
+type expQueue[K comparable] []*expEntry[K]
+
+func (q expQueue[K]) Len() int           { return len(q) }
+func (q expQueue[K]) Less(i, j int) bool { return q[i].exp < q[j].exp }
+
+func (q expQueue[K]) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *expQueue[K]) Push(x any) {
+	e := x.(*expEntry[K])
+	e.index = len(*q)
+	*q = append(*q, e)
+}
+
+func (q *expQueue[K]) Pop() any {
+	old := *q
+	n := len(old)
+	e := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+
+	return e
+}
+
+// expPolicy orders the items by their expiration time.
+type expPolicy[K comparable] struct {
+	queue   expQueue[K]
+	entries map[K]*expEntry[K]
+}
+
+func newExpPolicy[K comparable]() *expPolicy[K] {
+	return &expPolicy[K]{
+		entries: make(map[K]*expEntry[K]),
+	}
+}
+
+func (p *expPolicy[K]) add(key K, exp int64) {
+	// The items which never expire should be evicted last.
+	if exp <= 0 {
+		exp = math.MaxInt64
+	}
+	if e, ok := p.entries[key]; ok {
+		e.exp = exp
+		heap.Fix(&p.queue, e.index)
+		return
+	}
+	e := &expEntry[K]{key: key, exp: exp}
+	heap.Push(&p.queue, e)
+	p.entries[key] = e
+}
+
+func (p *expPolicy[K]) access(K) {}
+
+func (p *expPolicy[K]) remove(key K) {
+	if e, ok := p.entries[key]; ok {
+		heap.Remove(&p.queue, e.index)
+		delete(p.entries, key)
+	}
+}
+
+func (p *expPolicy[K]) victim() (key K, ok bool) {
+	if len(p.queue) > 0 {
+		return p.queue[0].key, true
+	}
+	return
+}
+
+func (p *expPolicy[K]) reset() {
+	p.queue = nil
+	p.entries = make(map[K]*expEntry[K])
+}
@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_MaxEntries(t *testing.T) {
+	assert := assert.New(t)
+
+	var evicted []string
+
+	c := New[string, int](NoExpiration, 0, WithMaxEntries(3), WithStats())
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		if reason == EvictCapacity {
+			evicted = append(evicted, key)
+		}
+	})
+
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, NoExpiration)
+	c.Set("c", 3, time.Second)
+	c.Set("d", 4, time.Hour)
+	assert.Equal(3, c.Count())
+	// The item closest to its expiration time should be evicted first.
+	assert.Equal([]string{"c"}, evicted)
+
+	c.Set("e", 5, 2*time.Hour)
+	assert.Equal([]string{"c", "a"}, evicted)
+
+	// The items without expiration time are evicted last.
+	c.Set("f", 6, NoExpiration)
+	c.Set("g", 7, NoExpiration)
+	assert.Equal([]string{"c", "a", "d", "e"}, evicted)
+	assert.Equal(3, c.Count())
+	assert.Equal(uint64(4), c.Stats().Evictions)
+
+	// Replacing an existing item should not evict anything.
+	c.Update("g", 8, time.Millisecond)
+	assert.Equal(3, c.Count())
+	assert.Len(evicted, 4)
+
+	c.Delete("b")
+	c.Set("h", 9, NoExpiration)
+	assert.Len(evicted, 4)
+
+	c.Flush()
+	c.Set("a", 1, NoExpiration)
+	c.Set("b", 1, NoExpiration)
+	c.Set("c", 1, NoExpiration)
+	assert.Equal(3, c.Count())
+
+	// The new item is stored even if it's the closest to its expiration time.
+	evicted = nil
+	assert.NoError(c.Set("i", 10, time.Minute))
+	item, err := c.Get("i")
+	assert.NoError(err)
+	assert.Equal(10, item.Val())
+	assert.Len(evicted, 1)
+	assert.NotContains(evicted, "i")
+	assert.Equal(3, c.Count())
+
+	// It's the first one evicted afterwards.
+	c.Set("j", 11, NoExpiration)
+	assert.Len(evicted, 2)
+	assert.Equal("i", evicted[1])
+}
+
+func TestCache_MaxEntriesLRU(t *testing.T) {
+	assert := assert.New(t)
+
+	var evicted []string
+
+	c := New[string, int](NoExpiration, 0, WithMaxEntries(3), WithEvictionPolicy(PolicyLRU))
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", 1, DefaultExpiration)
+	c.Set("b", 2, DefaultExpiration)
+	c.Set("c", 3, DefaultExpiration)
+	c.Get("a")
+	c.Set("d", 4, DefaultExpiration)
+	assert.Equal([]string{"b"}, evicted)
+
+	c.GetOrLoad("c", func(string) (int, time.Duration, error) {
+		return 0, DefaultExpiration, nil
+	})
+	c.Set("e", 5, DefaultExpiration)
+	assert.Equal([]string{"b", "a"}, evicted)
+
+	_, err := c.Get("c")
+	assert.NoError(err)
+	_, err = c.Get("a")
+	assert.Error(err)
+}
+
+func TestCache_MaxCost(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, string](NoExpiration, 0,
+		WithMaxCost(10),
+		WithCost(func(v string) int64 { return int64(len(v)) }),
+		WithEvictionPolicy(PolicyLRU),
+	)
+
+	assert.NoError(c.Set("a", "aaaa", DefaultExpiration))
+	assert.NoError(c.Set("b", "bbbb", DefaultExpiration))
+	assert.NoError(c.Set("c", "cc", DefaultExpiration))
+	assert.Equal(3, c.Count())
+	assert.Equal(int64(10), c.cost)
+
+	assert.NoError(c.Set("d", "ddd", DefaultExpiration))
+	assert.Equal(3, c.Count())
+	assert.Equal(int64(9), c.cost)
+	_, err := c.Get("a")
+	assert.Error(err)
+
+	// Updating an item takes into account the cost difference.
+	assert.NoError(c.Update("c", "c", DefaultExpiration))
+	assert.Equal(int64(8), c.cost)
+
+	err = c.Set("e", "eeeeeeeeeee", DefaultExpiration)
+	assert.Error(err)
+	assert.Equal(3, c.Count())
+
+	c.Delete("b")
+	assert.Equal(int64(4), c.cost)
+
+	assert.Panics(func() {
+		New[string, int](NoExpiration, 0, WithCost(func(v string) int64 { return 1 }))
+	})
+}
@@ -0,0 +1,237 @@
+package cache
+
+const (
+	// s3fifoSmallRatio is the ratio of the cache size reserved for the small queue.
+	s3fifoSmallRatio = 0.1
+	// s3fifoMaxFreq is the maximum value of the access counter of an entry.
+	s3fifoMaxFreq = 3
+)
+
+// s3fifoEntry holds a value together with its access counter.
+type s3fifoEntry[V any] struct {
+	value V
+	freq  uint8
+}
+
+// S3FIFOCache implements a fixed size S3-FIFO cache, which uses three FIFO queues: a small one for the new values,
+// a main one for the values accessed again while being in the small queue and a ghost one remembering the keys
+// recently evicted from the small queue. Most of the one-hit values are evicted quickly from the small queue,
+// which makes the cache resistant to the scans. The accesses only increment a counter, without reordering the queues.
+type S3FIFOCache[K comparable, V any] struct {
+	small     *lruMap[K, *s3fifoEntry[V]]
+	main      *lruMap[K, *s3fifoEntry[V]]
+	ghost     *lruMap[K, struct{}]
+	size      int
+	smallSize int
+	stats     *statsCounter
+	admission *tinyLFU[K]
+	window    *admissionWindow[K, V]
+}
+
+// NewS3FIFO initializes a new S3-FIFO cache. The WithStats option enables collecting the cache statistics
+// and the WithTinyLFU option enables the W-TinyLFU admission policy.
+func NewS3FIFO[K comparable, V any](size int, opts ...Option) (*S3FIFOCache[K, V], error) {
+	if size <= 0 {
+		return nil, errInvalidSize
+	}
+
+	cfg := newConfig(opts)
+	window := newAdmissionWindow[K, V](cfg.tinyLFU, size)
+	admission := newTinyLFU[K](cfg.tinyLFU, size)
+	size -= window.capacity()
+
+	return &S3FIFOCache[K, V]{
+		small:     newLRUMap[K, *s3fifoEntry[V]](),
+		main:      newLRUMap[K, *s3fifoEntry[V]](),
+		ghost:     newLRUMap[K, struct{}](),
+		size:      size,
+		smallSize: atLeastOne(float64(size) * s3fifoSmallRatio),
+		stats:     newStatsCounter(cfg.stats),
+		admission: admission,
+		window:    window,
+	}, nil
+}
+
+// entry returns the entry stored under the key in either the small or the main queue.
+func (c *S3FIFOCache[K, V]) entry(key K) (*s3fifoEntry[V], bool) {
+	if nd, ok := c.small.get(key); ok {
+		return nd.value, true
+	}
+	if nd, ok := c.main.get(key); ok {
+		return nd.value, true
+	}
+	return nil, false
+}
+
+// Add adds a value to the cache. If a value is evicted, this value and the key for it is returned.
+// In case the W-TinyLFU admission policy is enabled, the new value enters the admission window and the value
+// pushed out of it might not be admitted into the cache, in which case it is returned as the evicted value.
+func (c *S3FIFOCache[K, V]) Add(key K, value V) (oldestKey K, oldestValue V, removed bool) {
+	c.admission.record(key)
+
+	if e, ok := c.entry(key); ok {
+		e.value = value
+		c.stats.update()
+		return
+	}
+	if c.window.update(key, value) {
+		c.stats.update()
+		return
+	}
+
+	// The new value enters the admission window and the value pushed out of it competes for the cache.
+	// Without an admission window, the new value itself competes for the cache.
+	key, value, ok := c.window.push(key, value)
+	if !ok {
+		return
+	}
+
+	if c.small.len()+c.main.len() >= c.size {
+		if victim, ok := c.victim(); ok && !c.admission.admit(key, victim) {
+			c.stats.reject()
+			return c.window.rejected(key, value)
+		}
+		oldestKey, oldestValue, removed = c.evict()
+	}
+	// The keys evicted recently from the small queue are inserted directly into the main queue.
+	if _, ok := c.ghost.remove(key); ok {
+		c.main.pushFront(key, &s3fifoEntry[V]{value: value})
+	} else {
+		c.small.pushFront(key, &s3fifoEntry[V]{value: value})
+	}
+	c.stats.set()
+
+	return
+}
+
+// next prepares the queues for an eviction. It moves the values accessed while being in the small queue
+// into the main queue and reinserts the values accessed while being in the main queue with a decremented counter,
+// until the oldest value of one of the queues is a value which was not accessed. It returns that queue.
+// The small queue is used if it exceeds its target size, otherwise the main queue.
+func (c *S3FIFOCache[K, V]) next() *lruMap[K, *s3fifoEntry[V]] {
+	if c.small.len() >= c.smallSize || c.main.len() == 0 {
+		for nd := c.small.list.last(); nd != &c.small.list.root; nd = c.small.list.last() {
+			if nd.value.freq == 0 {
+				return c.small
+			}
+			c.small.remove(nd.key)
+			nd.value.freq = 0
+			c.main.pushFront(nd.key, nd.value)
+		}
+	}
+	for nd := c.main.list.last(); nd != &c.main.list.root; nd = c.main.list.last() {
+		if nd.value.freq == 0 {
+			return c.main
+		}
+		nd.value.freq--
+		c.main.moveFront(nd)
+	}
+	return nil
+}
+
+// victim returns the key of the value which would be evicted next.
+func (c *S3FIFOCache[K, V]) victim() (key K, ok bool) {
+	if q := c.next(); q != nil {
+		return q.oldest()
+	}
+	return
+}
+
+// evict removes the next value which was not accessed. The keys of the values
+// evicted from the small queue are remembered in the ghost queue.
+func (c *S3FIFOCache[K, V]) evict() (key K, value V, removed bool) {
+	q := c.next()
+	if q == nil {
+		return
+	}
+
+	key, e, _ := q.removeOldest()
+	if q == c.small {
+		if c.ghost.len() >= c.size-c.smallSize {
+			c.ghost.removeOldest()
+		}
+		c.ghost.pushFront(key, struct{}{})
+	}
+	c.stats.evicted(EvictCapacity, 1)
+
+	return key, e.value, true
+}
+
+// Get returns the element for the key if the element is present in the cache and increments its access counter.
+func (c *S3FIFOCache[K, V]) Get(key K) (value V, available bool) {
+	c.admission.record(key)
+	if e, ok := c.entry(key); ok {
+		if e.freq < s3fifoMaxFreq {
+			e.freq++
+		}
+		c.stats.hit()
+		return e.value, true
+	}
+	if value, available = c.window.get(key); available {
+		c.stats.hit()
+		return
+	}
+	c.stats.miss()
+	return
+}
+
+// Peek returns the element for the key if the element is present in the cache, without incrementing its access counter.
+func (c *S3FIFOCache[K, V]) Peek(key K) (value V, available bool) {
+	if e, ok := c.entry(key); ok {
+		return e.value, true
+	}
+	return c.window.peek(key)
+}
+
+// Contains reports whether the key is present in the cache, without incrementing its access counter.
+func (c *S3FIFOCache[K, V]) Contains(key K) bool {
+	return c.small.contains(key) || c.main.contains(key) || c.window.contains(key)
+}
+
+// Remove removes an element from the cache denoted by the key. The value removed is returned.
+func (c *S3FIFOCache[K, V]) Remove(key K) (value V, removed bool) {
+	e, ok := c.small.remove(key)
+	if !ok {
+		e, ok = c.main.remove(key)
+	}
+	if !ok {
+		if value, removed = c.window.remove(key); removed {
+			c.stats.evicted(EvictDeleted, 1)
+		}
+		return
+	}
+	c.stats.evicted(EvictDeleted, 1)
+
+	return e.value, true
+}
+
+// Count returns the number of the current values from the cache.
+func (c *S3FIFOCache[K, V]) Count() int {
+	return c.small.len() + c.main.len() + c.window.len()
+}
+
+// Flush clears all values from the cache.
+func (c *S3FIFOCache[K, V]) Flush() {
+	c.stats.evicted(EvictFlushed, c.Count())
+	c.small.reset()
+	c.main.reset()
+	c.ghost.reset()
+	c.window.reset()
+	c.admission.reset()
+}
+
+// Stats returns a snapshot of the cache statistics.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (c *S3FIFOCache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (c *S3FIFOCache[K, V]) ResetStats() {
+	c.stats.reset()
+}
+
+// AsStore returns an adapter exposing the S3-FIFO cache through the Store interface.
+func (c *S3FIFOCache[K, V]) AsStore() Store[K, V] {
+	return boundedStore[K, V]{c}
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestS3FIFOCache_Eviction(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewS3FIFO[int, int](10)
+	for i := 0; i < 10; i++ {
+		c.Add(i, i)
+	}
+	c.Get(0)
+	c.Get(1)
+
+	// The values accessed while being in the small queue are moved into the main queue,
+	// while the first value which was not accessed is evicted and remembered in the ghost queue.
+	key, _, removed := c.Add(10, 10)
+	assert.True(removed)
+	assert.Equal(2, key)
+	assert.True(c.main.contains(0))
+	assert.True(c.main.contains(1))
+	assert.True(c.ghost.contains(2))
+
+	// The value added again shortly after its eviction goes directly into the main queue.
+	c.Add(11, 11)
+	c.Add(2, 2)
+	assert.True(c.main.contains(2))
+	assert.False(c.ghost.contains(2))
+	assert.Equal(10, c.Count())
+}
@@ -0,0 +1,338 @@
+package cache
+
+import (
+	"errors"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+	"github.com/esimov/gogu/internal/keys"
+)
+
+type sharded[K comparable, V any] struct {
+	mu         sync.Mutex
+	shards     []*Cache[K, V]
+	done       chan struct{}
+	stopped    chan struct{}
+	cleanupInt time.Duration
+	clock      clock.Clock
+	closed     bool
+}
+
+// Sharded is a cache partitioned into a fixed number of independent shards.
+// Each key is hashed to exactly one shard, which means that operations on keys
+// living in different shards don't contend for the same lock.
+// It is recommended under highly concurrent workloads, where the single lock of Cache becomes a bottleneck.
+type Sharded[K comparable, V any] struct {
+	*sharded[K, V]
+}
+
+// NewSharded instantiates a sharded cache with the provided number of shards.
+// The expiration time and the cleanup interval have the same meaning as in the case of New,
+// but only one cleanup goroutine is started, which removes the expired items from all the shards.
+// The provided options are applied on each shard, while the clock set by WithClock also schedules the cleanup.
+// The limits set by WithMaxEntries and WithMaxCost apply to the whole cache, so they are divided evenly
+// (rounded up) across the shards. Since each shard evicts its items independently, the cache might
+// start evicting before reaching the limits when the keys are not evenly distributed across the shards.
+func NewSharded[K comparable, V any](shards int, expTime, cleanupTime time.Duration, opts ...Option) (*Sharded[K, V], error) {
+	if shards <= 0 {
+		return nil, errors.New("the number of shards must be a positive value")
+	}
+
+	cfg := newConfig(opts)
+	s := &sharded[K, V]{
+		shards:     make([]*Cache[K, V], shards),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+		cleanupInt: cleanupTime,
+		clock:      cfg.clock,
+	}
+	shardOpts := append(opts[:len(opts):len(opts)], func(c *config) {
+		c.maxEntries = int(perShard(int64(cfg.maxEntries), shards))
+		c.maxCost = perShard(cfg.maxCost, shards)
+	})
+	for i := range s.shards {
+		s.shards[i] = New[K, V](expTime, 0, shardOpts...)
+	}
+	S := &Sharded[K, V]{s}
+
+	if cleanupTime > 0 {
+		go s.cleanup()
+		runtime.SetFinalizer(S, stopShardedCleanup[K, V])
+	} else {
+		close(s.stopped)
+	}
+
+	return S, nil
+}
+
+// perShard divides the limit across the shards, rounding up. A non-positive limit means no limit.
+func perShard(limit int64, shards int) int64 {
+	if limit <= 0 {
+		return limit
+	}
+	return (limit + int64(shards) - 1) / int64(shards)
+}
+
+// shard returns the shard responsible for the provided key using the FNV-1a hash function.
+func (s *sharded[K, V]) shard(key K) *Cache[K, V] {
+	return s.shards[keys.Hash(key)%uint64(len(s.shards))]
+}
+
+// Set inserts a new item into the shard the key belongs to.
+// In case an item with the specified key already exists it will return an error.
+func (s *Sharded[K, V]) Set(key K, val V, d time.Duration) error {
+	return s.shard(key).Set(key, val, d)
+}
+
+// SetDefault adds a new item into the cache with the default expiration time.
+func (s *Sharded[K, V]) SetDefault(key K, val V) error {
+	return s.shard(key).SetDefault(key, val)
+}
+
+// SetWithTags inserts a new item into the cache and associates it with the provided tags.
+func (s *Sharded[K, V]) SetWithTags(key K, val V, d time.Duration, tags ...string) error {
+	return s.shard(key).SetWithTags(key, val, d, tags...)
+}
+
+// Get returns a cache item defined by its key. If the item is expired an error is returned.
+func (s *Sharded[K, V]) Get(key K) (*Item[V], error) {
+	return s.shard(key).Get(key)
+}
+
+// Update replaces a cache item with the new value.
+func (s *Sharded[K, V]) Update(key K, val V, d time.Duration) error {
+	return s.shard(key).Update(key, val, d)
+}
+
+// GetOrLoad returns the value of a cache item, loading it on miss. See Cache.GetOrLoad for more details.
+func (s *Sharded[K, V]) GetOrLoad(key K, fn func(K) (V, time.Duration, error)) (V, error) {
+	return s.shard(key).GetOrLoad(key, fn)
+}
+
+// Compute atomically replaces the value of an item with the result of the provided function.
+// See Cache.Compute for more details.
+func (s *Sharded[K, V]) Compute(key K, fn func(old V, ok bool) (V, bool)) (V, error) {
+	return s.shard(key).Compute(key, fn)
+}
+
+// GetAndDelete atomically returns and removes a cache item.
+func (s *Sharded[K, V]) GetAndDelete(key K) (*Item[V], error) {
+	return s.shard(key).GetAndDelete(key)
+}
+
+// Delete removes a cache item.
+func (s *Sharded[K, V]) Delete(key K) error {
+	return s.shard(key).Delete(key)
+}
+
+// Touch renews the expiration time of an existing item. See Cache.Touch for more details.
+func (s *Sharded[K, V]) Touch(key K, d time.Duration) error {
+	return s.shard(key).Touch(key, d)
+}
+
+// IsExpired checks if a cache item is expired.
+func (s *Sharded[K, V]) IsExpired(key K) bool {
+	return s.shard(key).IsExpired(key)
+}
+
+// DeleteExpired removes all the expired items from every shard.
+func (s *sharded[K, V]) DeleteExpired() error {
+	var err error
+
+	for _, c := range s.shards {
+		if e := c.DeleteExpired(); e != nil {
+			err = errors.Join(err, e)
+		}
+	}
+
+	return err
+}
+
+// InvalidateTag removes all the items associated with the tag from every shard
+// and returns the number of removed items.
+func (s *Sharded[K, V]) InvalidateTag(tag string) int {
+	var n int
+	for _, c := range s.shards {
+		n += c.InvalidateTag(tag)
+	}
+
+	return n
+}
+
+// DeleteFunc removes all the items for which the provided function returns true from every shard
+// and returns the number of removed items.
+func (s *Sharded[K, V]) DeleteFunc(fn func(key K, val V) bool) int {
+	var n int
+	for _, c := range s.shards {
+		n += c.DeleteFunc(fn)
+	}
+
+	return n
+}
+
+// Flush removes all the existing items from every shard.
+func (s *Sharded[K, V]) Flush() {
+	for _, c := range s.shards {
+		c.Flush()
+	}
+}
+
+// Count returns the number of existing items summed up across all the shards.
+func (s *Sharded[K, V]) Count() int {
+	var n int
+	for _, c := range s.shards {
+		n += c.Count()
+	}
+
+	return n
+}
+
+// List returns the items which are not expired from all the shards merged into a newly allocated map.
+func (s *Sharded[K, V]) List() map[K]*Item[V] {
+	items := make(map[K]*Item[V])
+	for _, c := range s.shards {
+		for k, v := range c.List() {
+			items[k] = v
+		}
+	}
+
+	return items
+}
+
+// Snapshot returns the keys and the values of the items which are not expired from all the shards.
+func (s *Sharded[K, V]) Snapshot() map[K]V {
+	items := make(map[K]V)
+	for _, c := range s.shards {
+		c.Range(func(k K, v V) bool {
+			items[k] = v
+			return true
+		})
+	}
+
+	return items
+}
+
+// Keys returns the keys of the items which are not expired from all the shards.
+func (s *Sharded[K, V]) Keys() []K {
+	var keys []K
+	for _, c := range s.shards {
+		keys = append(keys, c.Keys()...)
+	}
+
+	return keys
+}
+
+// Range calls fn sequentially for each item which is not expired, shard by shard.
+// If fn returns false, the iteration stops. See Cache.Range for more details.
+func (s *Sharded[K, V]) Range(fn func(key K, val V) bool) {
+	for _, c := range s.shards {
+		stopped := false
+		c.Range(func(k K, v V) bool {
+			if !fn(k, v) {
+				stopped = true
+				return false
+			}
+			return true
+		})
+		if stopped {
+			return
+		}
+	}
+}
+
+// RangeKeys calls fn sequentially for the key of each item which is not expired, shard by shard.
+// If fn returns false, the iteration stops. See Cache.Range for more details.
+func (s *Sharded[K, V]) RangeKeys(fn func(key K) bool) {
+	s.Range(func(k K, _ V) bool {
+		return fn(k)
+	})
+}
+
+// MapToCache transfers the map values into the cache.
+func (s *Sharded[K, V]) MapToCache(m map[K]V, d time.Duration) error {
+	var err error
+
+	for k, v := range m {
+		if e := s.Set(k, v, d); e != nil {
+			err = errors.Join(err, e)
+		}
+	}
+
+	return err
+}
+
+// Stats returns the statistics summed up across all the shards.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (s *Sharded[K, V]) Stats() Stats {
+	var stats Stats
+	for _, c := range s.shards {
+		stats = stats.add(c.Stats())
+	}
+
+	return stats
+}
+
+// ResetStats sets the statistics counters of all the shards back to zero.
+func (s *Sharded[K, V]) ResetStats() {
+	for _, c := range s.shards {
+		c.ResetStats()
+	}
+}
+
+// OnEvicted registers the eviction callback on every shard. See Cache.OnEvicted for more details.
+func (s *Sharded[K, V]) OnEvicted(fn func(key K, val V, reason EvictReason)) {
+	for _, c := range s.shards {
+		c.OnEvicted(fn)
+	}
+}
+
+// Close stops the cleanup goroutine and closes all the shards. It returns once the cleanup goroutine has exited.
+// Any subsequent operation which returns an error will return ErrClosed. See Cache.Close for more details.
+func (s *Sharded[K, V]) Close() error {
+	runtime.SetFinalizer(s, nil)
+
+	return s.close()
+}
+
+// close has a local scope only. It closes the shards and stops the cleanup goroutine.
+func (s *sharded[K, V]) close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	close(s.done)
+	s.mu.Unlock()
+
+	<-s.stopped
+	for _, c := range s.shards {
+		c.Close()
+	}
+
+	return nil
+}
+
+// cleanup removes the expired items from all the shards at the specified time interval.
+// The stopped channel is closed once the cleanup goroutine returns.
+func (s *sharded[K, V]) cleanup() {
+	defer close(s.stopped)
+	ticker := s.clock.NewTicker(s.cleanupInt)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.DeleteExpired()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// stopShardedCleanup stops the cleanup process once the sharded cache became unreachable.
+func stopShardedCleanup[K comparable, V any](s *Sharded[K, V]) {
+	s.close()
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharded_Basic(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewSharded[string, int](0, DefaultExpiration, 0)
+	assert.Error(err)
+
+	c, err := NewSharded[string, int](8, DefaultExpiration, time.Minute)
+	assert.NoError(err)
+	defer c.Close()
+
+	for i := 0; i < 100; i++ {
+		err = c.Set("key"+strconv.Itoa(i), i, DefaultExpiration)
+		assert.NoError(err)
+	}
+	assert.Equal(100, c.Count())
+	assert.Len(c.List(), 100)
+
+	err = c.Set("key1", 1, DefaultExpiration)
+	assert.Error(err)
+
+	item, err := c.Get("key10")
+	assert.NoError(err)
+	assert.Equal(10, item.Val())
+
+	err = c.Update("key10", 20, DefaultExpiration)
+	assert.NoError(err)
+	item, _ = c.Get("key10")
+	assert.Equal(20, item.Val())
+
+	err = c.Delete("key10")
+	assert.NoError(err)
+	_, err = c.Get("key10")
+	assert.Error(err)
+	assert.Equal(99, c.Count())
+
+	// The keys should be distributed across more than one shard.
+	var used int
+	for _, shard := range c.shards {
+		if shard.Count() > 0 {
+			used++
+		}
+	}
+	assert.Greater(used, 1)
+
+	c.Flush()
+	assert.Equal(0, c.Count())
+}
+
+func TestSharded_Limits(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := NewSharded[string, int](4, DefaultExpiration, 0, WithMaxEntries(10), WithMaxCost(100))
+	assert.NoError(err)
+	defer c.Close()
+
+	// The limits are divided across the shards, rounding up.
+	for _, shard := range c.shards {
+		assert.Equal(3, shard.maxEntries)
+		assert.Equal(int64(25), shard.maxCost)
+	}
+
+	for i := 0; i < 100; i++ {
+		c.Set("key"+strconv.Itoa(i), i, DefaultExpiration)
+	}
+	assert.LessOrEqual(c.Count(), 12)
+}
+
+func TestSharded_Expiration(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewSharded[string, int](4, NoExpiration, 0)
+	c.Set("a", 1, 1*time.Millisecond)
+	c.Set("b", 2, 1*time.Millisecond)
+	c.Set("c", 3, NoExpiration)
+
+	var evicted []string
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		assert.Equal(EvictExpired, reason)
+		evicted = append(evicted, key)
+	})
+
+	<-time.After(5 * time.Millisecond)
+	err := c.DeleteExpired()
+	assert.NoError(err)
+	assert.Equal(1, c.Count())
+	assert.ElementsMatch([]string{"a", "b"}, evicted)
+
+	c2, _ := NewSharded[string, int](4, 5*time.Millisecond, 10*time.Millisecond)
+	c2.SetDefault("a", 1)
+	c2.Set("b", 2, NoExpiration)
+	<-time.After(50 * time.Millisecond)
+	assert.Equal(1, c2.Count())
+
+	assert.NoError(c2.Close())
+	assert.ErrorIs(c2.Close(), ErrClosed)
+	assert.ErrorIs(c2.Set("c", 3, DefaultExpiration), ErrClosed)
+}
+
+func TestSharded_Clock(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+	c, _ := NewSharded[string, int](4, time.Minute, time.Hour, WithClock(clk))
+	defer c.Close()
+
+	c.SetDefault("a", 1)
+	c.SetDefault("b", 2)
+	c.Set("c", 3, NoExpiration)
+
+	// The cleanup runs once the clock is advanced by the cleanup interval.
+	evicted := make(chan string, 2)
+	c.OnEvicted(func(key string, _ int, _ EvictReason) {
+		evicted <- key
+	})
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	assert.ElementsMatch([]string{"a", "b"}, []string{<-evicted, <-evicted})
+	assert.Equal(1, c.Count())
+}
+
+func benchmarkParallel(b *testing.B, set func(string, int) error, get func(string) (*Item[int], error)) {
+	keys := make([]string, 1024)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+		set(keys[i], i)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var i int
+		for pb.Next() {
+			key := keys[i%len(keys)]
+			if i%10 == 0 {
+				set(key, i)
+			} else {
+				get(key)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkCache_Parallel(b *testing.B) {
+	c := New[string, int](NoExpiration, 0)
+	defer c.Close()
+
+	benchmarkParallel(b, func(k string, v int) error {
+		return c.Update(k, v, DefaultExpiration)
+	}, c.Get)
+}
+
+func BenchmarkSharded_Parallel(b *testing.B) {
+	c, _ := NewSharded[string, int](32, NoExpiration, 0)
+	defer c.Close()
+
+	benchmarkParallel(b, func(k string, v int) error {
+		return c.Update(k, v, DefaultExpiration)
+	}, c.Get)
+}
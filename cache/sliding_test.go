@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_SlidingExpiration(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	c := New[string, int](50*time.Millisecond, 0, WithSlidingExpiration(), WithClock(clk))
+	c.SetDefault("a", 1)
+	c.SetDefault("b", 2)
+	c.Set("c", 3, NoExpiration)
+
+	// Reading the item before its expiration renews its lifetime.
+	for i := 0; i < 4; i++ {
+		clk.Advance(25 * time.Millisecond)
+		_, err := c.Get("a")
+		assert.NoError(err)
+	}
+
+	assert.False(c.IsExpired("a"))
+	assert.True(c.IsExpired("b"))
+	assert.False(c.IsExpired("c"))
+	assert.False(c.IsExpired("d"))
+
+	_, err := c.Get("b")
+	assert.Error(err)
+
+	err = c.DeleteExpired()
+	assert.NoError(err)
+	assert.Equal(2, c.Count())
+	assert.False(c.IsExpired("b"))
+
+	// The expired items are not renewed on reads.
+	clk.Advance(70 * time.Millisecond)
+	_, err = c.Get("a")
+	assert.Error(err)
+	assert.True(c.IsExpired("a"))
+
+	item, err := c.Get("c")
+	assert.NoError(err)
+	assert.Equal(int64(NoExpiration), item.expiration)
+}
+
+func TestCache_Touch(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	c := New[string, int](NoExpiration, 0, WithClock(clk))
+	c.Set("a", 1, 20*time.Millisecond)
+	c.Set("b", 2, 20*time.Millisecond)
+
+	// Without the sliding expiration mode the reads do not renew the lifetime.
+	clk.Advance(10 * time.Millisecond)
+	c.Get("a")
+	assert.NoError(c.Touch("b", 100*time.Millisecond))
+
+	clk.Advance(20 * time.Millisecond)
+	assert.True(c.IsExpired("a"))
+	assert.False(c.IsExpired("b"))
+
+	err := c.Touch("a", time.Minute)
+	assert.Error(err)
+	err = c.Touch("x", time.Minute)
+	assert.Error(err)
+
+	assert.NoError(c.Touch("b", NoExpiration))
+	clk.Advance(100 * time.Millisecond)
+	c.DeleteExpired()
+	assert.Equal(1, c.Count())
+	item, err := c.Get("b")
+	assert.NoError(err)
+	assert.Equal(2, item.Val())
+
+	c.Close()
+	assert.ErrorIs(c.Touch("b", time.Minute), ErrClosed)
+}
+
+func TestCache_SlidingExpirationConcurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, int](time.Minute, 0, WithSlidingExpiration())
+	assert.NoError(c.SetDefault("a", 1))
+
+	loader := func(string) (int, time.Duration, error) {
+		return 2, DefaultExpiration, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				item, err := c.Get("a")
+				assert.NoError(err)
+				assert.False(item.expired(time.Now().UnixNano()))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				val, err := c.GetOrLoad("a", loader)
+				assert.NoError(err)
+				assert.Equal(1, val)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.NoError(c.Touch("a", DefaultExpiration))
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds a point in time snapshot of the cache statistics.
+type Stats struct {
+	// Hits is the number of lookups which found an existing item.
+	Hits uint64
+	// Misses is the number of lookups which haven't found an item, or found an expired one.
+	Misses uint64
+	// Expirations is the number of expired items removed from the cache.
+	Expirations uint64
+	// Evictions is the number of items removed from the cache for any other reason than their expiration,
+	// like deleting, flushing or evicting them because the cache was full.
+	Evictions uint64
+	// Sets is the number of newly inserted items.
+	Sets uint64
+	// Updates is the number of existing items replaced with a new value.
+	Updates uint64
+	// Rejections is the number of new items not admitted into the cache by the admission filter.
+	Rejections uint64
+	// Loads is the number of loader function invocations.
+	Loads uint64
+	// LoadErrors is the number of loader function invocations which returned an error.
+	LoadErrors uint64
+	// LoadTime is the total time spent in the loader functions.
+	LoadTime time.Duration
+}
+
+// HitRatio returns the ratio between the hits and the total number of lookups.
+func (s Stats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// AvgLoadTime returns the average time spent in a loader function.
+func (s Stats) AvgLoadTime() time.Duration {
+	if s.Loads == 0 {
+		return 0
+	}
+	return s.LoadTime / time.Duration(s.Loads)
+}
+
+// add returns the sum of two statistics.
+func (s Stats) add(o Stats) Stats {
+	return Stats{
+		Hits:        s.Hits + o.Hits,
+		Misses:      s.Misses + o.Misses,
+		Expirations: s.Expirations + o.Expirations,
+		Evictions:   s.Evictions + o.Evictions,
+		Sets:        s.Sets + o.Sets,
+		Updates:     s.Updates + o.Updates,
+		Rejections:  s.Rejections + o.Rejections,
+		Loads:       s.Loads + o.Loads,
+		LoadErrors:  s.LoadErrors + o.LoadErrors,
+		LoadTime:    s.LoadTime + o.LoadTime,
+	}
+}
+
+// StatsProvider is implemented by all the cache types which are able to collect statistics.
+// It can be used as an adapter for exporting the statistics into an external metrics system.
+type StatsProvider interface {
+	// Stats returns a snapshot of the current statistics.
+	Stats() Stats
+	// ResetStats sets all the counters back to zero.
+	ResetStats()
+}
+
+// statsCounter maintains the cache statistics atomically.
+// A nil counter is valid and means that collecting the statistics is disabled.
+type statsCounter struct {
+	hits        atomic.Uint64
+	misses      atomic.Uint64
+	expirations atomic.Uint64
+	evictions   atomic.Uint64
+	sets        atomic.Uint64
+	updates     atomic.Uint64
+	rejections  atomic.Uint64
+	loads       atomic.Uint64
+	loadErrors  atomic.Uint64
+	loadTime    atomic.Int64
+}
+
+// newStatsCounter returns a new counter only if collecting the statistics is enabled.
+func newStatsCounter(enabled bool) *statsCounter {
+	if enabled {
+		return &statsCounter{}
+	}
+	return nil
+}
+
+func (s *statsCounter) hit() {
+	if s != nil {
+		s.hits.Add(1)
+	}
+}
+
+func (s *statsCounter) miss() {
+	if s != nil {
+		s.misses.Add(1)
+	}
+}
+
+func (s *statsCounter) set() {
+	if s != nil {
+		s.sets.Add(1)
+	}
+}
+
+func (s *statsCounter) update() {
+	if s != nil {
+		s.updates.Add(1)
+	}
+}
+
+func (s *statsCounter) reject() {
+	if s != nil {
+		s.rejections.Add(1)
+	}
+}
+
+// evicted records n items removed from the cache for the provided reason.
+func (s *statsCounter) evicted(reason EvictReason, n int) {
+	if s == nil {
+		return
+	}
+	switch reason {
+	case EvictExpired:
+		s.expirations.Add(uint64(n))
+	case EvictReplaced:
+		// The replaced items are counted as updates.
+	default:
+		s.evictions.Add(uint64(n))
+	}
+}
+
+// load records a loader function invocation.
+func (s *statsCounter) load(d time.Duration, err error) {
+	if s == nil {
+		return
+	}
+	s.loads.Add(1)
+	s.loadTime.Add(int64(d))
+	if err != nil {
+		s.loadErrors.Add(1)
+	}
+}
+
+// snapshot returns the current value of the counters.
+func (s *statsCounter) snapshot() Stats {
+	if s == nil {
+		return Stats{}
+	}
+	return Stats{
+		Hits:        s.hits.Load(),
+		Misses:      s.misses.Load(),
+		Expirations: s.expirations.Load(),
+		Evictions:   s.evictions.Load(),
+		Sets:        s.sets.Load(),
+		Updates:     s.updates.Load(),
+		Rejections:  s.rejections.Load(),
+		Loads:       s.loads.Load(),
+		LoadErrors:  s.loadErrors.Load(),
+		LoadTime:    time.Duration(s.loadTime.Load()),
+	}
+}
+
+// reset sets all the counters back to zero.
+func (s *statsCounter) reset() {
+	if s == nil {
+		return
+	}
+	s.hits.Store(0)
+	s.misses.Store(0)
+	s.expirations.Store(0)
+	s.evictions.Store(0)
+	s.sets.Store(0)
+	s.updates.Store(0)
+	s.rejections.Store(0)
+	s.loads.Store(0)
+	s.loadErrors.Store(0)
+	s.loadTime.Store(0)
+}
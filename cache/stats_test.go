@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_Stats(t *testing.T) {
+	assert := assert.New(t)
+
+	c1 := New[string, int](NoExpiration, 0)
+	c1.Set("a", 1, DefaultExpiration)
+	c1.Get("a")
+	assert.Equal(Stats{}, c1.Stats())
+
+	c2 := New[string, int](NoExpiration, 0, WithStats())
+	c2.Set("a", 1, DefaultExpiration)
+	c2.Set("b", 2, time.Millisecond)
+	c2.Update("a", 2, DefaultExpiration)
+	c2.Get("a")
+	c2.Get("a")
+	c2.Get("c")
+	<-time.After(5 * time.Millisecond)
+	c2.Get("b")
+	c2.DeleteExpired()
+	c2.Delete("a")
+
+	stats := c2.Stats()
+	assert.Equal(uint64(2), stats.Hits)
+	assert.Equal(uint64(2), stats.Misses)
+	assert.Equal(uint64(2), stats.Sets)
+	assert.Equal(uint64(1), stats.Updates)
+	assert.Equal(uint64(1), stats.Expirations)
+	assert.Equal(uint64(1), stats.Evictions)
+	assert.Equal(0.5, stats.HitRatio())
+
+	c2.MapToCache(map[string]int{"x": 1, "y": 2}, DefaultExpiration)
+	c2.Flush()
+	assert.Equal(uint64(3), c2.Stats().Evictions)
+
+	_, err := c2.GetOrLoad("d", func(string) (int, time.Duration, error) {
+		time.Sleep(2 * time.Millisecond)
+		return 1, DefaultExpiration, nil
+	})
+	assert.NoError(err)
+	_, err = c2.GetOrLoad("e", func(string) (int, time.Duration, error) {
+		return 0, DefaultExpiration, errors.New("failed")
+	})
+	assert.Error(err)
+
+	stats = c2.Stats()
+	assert.Equal(uint64(2), stats.Loads)
+	assert.Equal(uint64(1), stats.LoadErrors)
+	assert.GreaterOrEqual(stats.LoadTime, 2*time.Millisecond)
+	assert.Greater(stats.AvgLoadTime(), time.Duration(0))
+
+	c2.ResetStats()
+	assert.Equal(Stats{}, c2.Stats())
+}
+
+func TestLRUCache_Stats(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewLRU[string, int](2, WithStats())
+	c.Add("a", 1)
+	c.Add("b", 2)
+	c.Add("a", 3)
+	c.Add("c", 4)
+	c.Get("a")
+	c.Get("b")
+	c.Remove("a")
+
+	var p StatsProvider = c
+	stats := p.Stats()
+	assert.Equal(uint64(3), stats.Sets)
+	assert.Equal(uint64(1), stats.Updates)
+	assert.Equal(uint64(1), stats.Hits)
+	assert.Equal(uint64(1), stats.Misses)
+	assert.Equal(uint64(2), stats.Evictions)
+
+	p.ResetStats()
+	assert.Equal(Stats{}, c.Stats())
+}
+
+func TestSharded_Stats(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewSharded[string, int](4, NoExpiration, 0, WithStats())
+	for _, k := range []string{"a", "b", "c", "d"} {
+		c.Set(k, 1, DefaultExpiration)
+		c.Get(k)
+	}
+	c.Get("e")
+
+	stats := c.Stats()
+	assert.Equal(uint64(4), stats.Sets)
+	assert.Equal(uint64(4), stats.Hits)
+	assert.Equal(uint64(1), stats.Misses)
+
+	c.ResetStats()
+	assert.Equal(Stats{}, c.Stats())
+}
@@ -0,0 +1,115 @@
+package cache
+
+import "time"
+
+// Store is a common key-value storage interface implemented by the adapters of all the cache types.
+// It makes possible to swap the cache implementations behind a single abstraction.
+// An adapter is safe for concurrent use only if the cache it wraps is.
+type Store[K comparable, V any] interface {
+	// Get returns the value stored under the key and a flag reporting whether the value exists.
+	Get(key K) (V, bool)
+	// Set stores the value under the key, replacing the existing one.
+	Set(key K, val V) error
+	// Delete removes the value stored under the key and reports whether the value existed.
+	Delete(key K) bool
+	// Len returns the number of stored values.
+	Len() int
+	// Clear removes all the stored values.
+	Clear()
+}
+
+// ExpiringStore is implemented by the stores which support setting the expiration time of each value.
+type ExpiringStore[K comparable, V any] interface {
+	Store[K, V]
+	// SetWithTTL stores the value under the key with the provided expiration time, replacing the existing one.
+	// If the duration is 0 (or DefaultExpiration) the default expiration time of the store is used.
+	SetWithTTL(key K, val V, d time.Duration) error
+}
+
+// NewItem returns a cache item holding the provided value, which never expires.
+func NewItem[V any](val V) *Item[V] {
+	return &Item[V]{object: val, expiration: int64(NoExpiration)}
+}
+
+// ttlCache is implemented by the cache types storing items with an expiration time.
+type ttlCache[K comparable, V any] interface {
+	Get(key K) (*Item[V], error)
+	Update(key K, val V, d time.Duration) error
+	Delete(key K) error
+	Count() int
+	Flush()
+}
+
+// ttlStore adapts the cache types storing items with an expiration time to the Store interface.
+// The values are stored with the default expiration time of the cache.
+type ttlStore[K comparable, V any] struct {
+	c ttlCache[K, V]
+}
+
+func (s ttlStore[K, V]) Get(key K) (V, bool) {
+	var v V
+
+	item, err := s.c.Get(key)
+	if err != nil {
+		return v, false
+	}
+	return item.Val(), true
+}
+
+func (s ttlStore[K, V]) Set(key K, val V) error {
+	return s.c.Update(key, val, DefaultExpiration)
+}
+
+func (s ttlStore[K, V]) SetWithTTL(key K, val V, d time.Duration) error {
+	return s.c.Update(key, val, d)
+}
+
+func (s ttlStore[K, V]) Delete(key K) bool {
+	return s.c.Delete(key) == nil
+}
+
+func (s ttlStore[K, V]) Len() int {
+	return s.c.Count()
+}
+
+func (s ttlStore[K, V]) Clear() {
+	s.c.Flush()
+}
+
+// Stats returns the statistics of the underlying cache, if it supports collecting them.
+func (s ttlStore[K, V]) Stats() Stats {
+	if p, ok := s.c.(StatsProvider); ok {
+		return p.Stats()
+	}
+	return Stats{}
+}
+
+// ResetStats resets the statistics of the underlying cache, if it supports collecting them.
+func (s ttlStore[K, V]) ResetStats() {
+	if p, ok := s.c.(StatsProvider); ok {
+		p.ResetStats()
+	}
+}
+
+// AsStore returns an adapter exposing the cache through the Store interface.
+// The values are stored with the default expiration time of the cache.
+func (c *Cache[K, V]) AsStore() Store[K, V] {
+	return ttlStore[K, V]{c}
+}
+
+// AsStore returns an adapter exposing the sharded cache through the Store interface.
+// The values are stored with the default expiration time of the cache.
+func (s *Sharded[K, V]) AsStore() Store[K, V] {
+	return ttlStore[K, V]{s}
+}
+
+// AsStore returns an adapter exposing the two-tier cache through the Store interface.
+// The values are stored with the default expiration time of the cold tier.
+func (t *Tiered[K, V]) AsStore() Store[K, V] {
+	return ttlStore[K, V]{t}
+}
+
+// AsStore returns an adapter exposing the LRU cache through the Store interface.
+func (c *LRUCache[K, V]) AsStore() Store[K, V] {
+	return boundedStore[K, V]{c}
+}
@@ -0,0 +1,70 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStore_Adapters(t *testing.T) {
+	assert := assert.New(t)
+
+	lru, err := NewLRU[string, int](10)
+	assert.NoError(err)
+	sharded, err := NewSharded[string, int](4, time.Minute, 0)
+	assert.NoError(err)
+	tiered, err := NewTiered[string, int](2, time.Minute, 0)
+	assert.NoError(err)
+	syncLRU, err := NewSyncLRU[string, int](10, time.Minute)
+	assert.NoError(err)
+
+	stores := map[string]Store[string, int]{
+		"cache":   New[string, int](time.Minute, 0).AsStore(),
+		"lru":     lru.AsStore(),
+		"sharded": sharded.AsStore(),
+		"tiered":  tiered.AsStore(),
+		"syncLRU": syncLRU.AsStore(),
+	}
+
+	for name, s := range stores {
+		t.Run(name, func(t *testing.T) {
+			_, ok := s.Get("foo")
+			assert.False(ok)
+
+			assert.NoError(s.Set("foo", 1))
+			assert.NoError(s.Set("bar", 2))
+			// Set replaces the existing values.
+			assert.NoError(s.Set("foo", 3))
+			assert.Equal(2, s.Len())
+
+			val, ok := s.Get("foo")
+			assert.True(ok)
+			assert.Equal(3, val)
+
+			assert.True(s.Delete("foo"))
+			assert.False(s.Delete("foo"))
+			assert.Equal(1, s.Len())
+
+			s.Clear()
+			assert.Equal(0, s.Len())
+		})
+	}
+}
+
+func TestStore_Stats(t *testing.T) {
+	assert := assert.New(t)
+
+	s := New[string, int](time.Minute, 0, WithStats()).AsStore()
+	s.Set("foo", 1)
+	s.Get("foo")
+	s.Get("bar")
+
+	p, ok := s.(StatsProvider)
+	assert.True(ok)
+	assert.Equal(uint64(1), p.Stats().Hits)
+	assert.Equal(uint64(1), p.Stats().Misses)
+
+	p.ResetStats()
+	assert.Equal(Stats{}, p.Stats())
+}
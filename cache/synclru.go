@@ -0,0 +1,321 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+)
+
+// SyncLRU is a concurrency-safe, fixed size LRU cache supporting an optional expiration time for its entries.
+// The expired entries are removed lazily, once they are accessed, or by calling DeleteExpired.
+type SyncLRU[K comparable, V any] struct {
+	mu        sync.Mutex
+	lru       *LRUCache[K, *Item[V]]
+	expTime   time.Duration
+	stats     *statsCounter
+	onEvicted func(K, V, EvictReason)
+	clock     clock.Clock
+}
+
+var _ StatsProvider = (*SyncLRU[string, any])(nil)
+
+// NewSyncLRU initializes a new concurrency-safe LRU cache holding at most size entries.
+// If the expiration time is less than or equal to zero (or NoExpiration) the entries never expire,
+// otherwise they expire after the expiration time, unless a different duration is provided with AddWithTTL.
+// The WithStats option enables collecting the cache statistics, the WithTinyLFU option enables the W-TinyLFU
+// admission policy and the WithClock option sets the clock used for the expiration.
+func NewSyncLRU[K comparable, V any](size int, expTime time.Duration, opts ...Option) (*SyncLRU[K, V], error) {
+	lru, err := NewLRU[K, *Item[V]](size, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := newConfig(opts)
+	return &SyncLRU[K, V]{
+		lru:     lru,
+		expTime: expTime,
+		stats:   newStatsCounter(cfg.stats),
+		clock:   cfg.clock,
+	}, nil
+}
+
+// Add adds a value to the cache with the default expiration time.
+// It reports whether the least recently used entry has been evicted for making room to the new one.
+func (c *SyncLRU[K, V]) Add(key K, val V) bool {
+	return c.AddWithTTL(key, val, DefaultExpiration)
+}
+
+// AddWithTTL adds a value to the cache which expires after the provided duration.
+// If the duration is 0 (or DefaultExpiration) the cache default expiration time is used.
+// If the duration is < 0 (or NoExpiration), the entry never expires.
+// It reports whether the least recently used entry has been evicted for making room to the new one.
+func (c *SyncLRU[K, V]) AddWithTTL(key K, val V, d time.Duration) bool {
+	var evicted []eviction[K, V]
+
+	if d == DefaultExpiration {
+		d = c.expTime
+	}
+	item := &Item[V]{object: val, ttl: d}
+	if d > 0 {
+		item.expiration = c.clock.Now().Add(d).UnixNano()
+	}
+
+	c.mu.Lock()
+	old, exists := c.lru.Peek(key)
+	if exists {
+		reason := EvictReplaced
+		if old.expired(c.now()) {
+			reason = EvictExpired
+		}
+		evicted = append(evicted, eviction[K, V]{key, old.object, reason})
+		c.stats.update()
+	}
+	oldestKey, oldest, removed := c.lru.Add(key, item)
+	// The new entry might not be admitted by the admission filter.
+	if !exists && c.lru.Contains(key) {
+		c.stats.set()
+	}
+	if removed {
+		reason := EvictCapacity
+		if oldest.expired(c.now()) {
+			reason = EvictExpired
+		}
+		evicted = append(evicted, eviction[K, V]{oldestKey, oldest.object, reason})
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return removed
+}
+
+// Get returns the value stored under the key and marks it as recently used.
+// The expired entry is removed from the cache and reported as missing.
+func (c *SyncLRU[K, V]) Get(key K) (value V, available bool) {
+	var evicted []eviction[K, V]
+
+	c.mu.Lock()
+	item, ok := c.lru.Get(key)
+	if ok && item.expired(c.now()) {
+		c.lru.Remove(key)
+		evicted = append(evicted, eviction[K, V]{key, item.object, EvictExpired})
+		ok = false
+	}
+	if ok {
+		c.stats.hit()
+		value, available = item.object, true
+	} else {
+		c.stats.miss()
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return
+}
+
+// Peek returns the value stored under the key without marking it as recently used.
+// The expired entry is reported as missing.
+func (c *SyncLRU[K, V]) Peek(key K) (value V, available bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if item, ok := c.lru.Peek(key); ok && !item.expired(c.now()) {
+		return item.object, true
+	}
+	return
+}
+
+// Contains reports whether an unexpired entry exists under the key, without marking it as recently used.
+func (c *SyncLRU[K, V]) Contains(key K) bool {
+	_, ok := c.Peek(key)
+	return ok
+}
+
+// Remove removes the entry denoted by the key from the cache. The value removed is returned.
+func (c *SyncLRU[K, V]) Remove(key K) (value V, removed bool) {
+	c.mu.Lock()
+	item, ok := c.lru.Remove(key)
+	c.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	c.notify([]eviction[K, V]{{key, item.object, EvictDeleted}})
+
+	return item.object, true
+}
+
+// RemoveOldest removes the least recently used entry from the cache. The key/value pair removed is returned.
+func (c *SyncLRU[K, V]) RemoveOldest() (key K, value V, removed bool) {
+	c.mu.Lock()
+	key, item, ok := c.lru.RemoveOldest()
+	c.mu.Unlock()
+
+	if !ok {
+		return
+	}
+	c.notify([]eviction[K, V]{{key, item.object, EvictDeleted}})
+
+	return key, item.object, true
+}
+
+// Resize changes the capacity of the cache. In case the new capacity is smaller
+// than the number of the entries from the cache, the least recently used entries are evicted.
+// It returns the number of the evicted entries.
+func (c *SyncLRU[K, V]) Resize(size int) (int, error) {
+	var evicted []eviction[K, V]
+
+	c.mu.Lock()
+	now := c.now()
+	err := c.lru.resize(size, func(key K, item *Item[V]) {
+		reason := EvictCapacity
+		if item.expired(now) {
+			reason = EvictExpired
+		}
+		evicted = append(evicted, eviction[K, V]{key, item.object, reason})
+	})
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return len(evicted), err
+}
+
+// DeleteExpired removes all the expired entries from the cache and returns their number.
+func (c *SyncLRU[K, V]) DeleteExpired() int {
+	var evicted []eviction[K, V]
+
+	c.mu.Lock()
+	now := c.now()
+	c.lru.each(func(key K, item *Item[V]) {
+		if item.expired(now) {
+			c.lru.Remove(key)
+			evicted = append(evicted, eviction[K, V]{key, item.object, EvictExpired})
+		}
+	})
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return len(evicted)
+}
+
+// Count returns the number of entries from the cache, including the expired ones not removed yet.
+func (c *SyncLRU[K, V]) Count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Count()
+}
+
+// Flush removes all the entries from the cache.
+func (c *SyncLRU[K, V]) Flush() {
+	var evicted []eviction[K, V]
+
+	c.mu.Lock()
+	c.lru.each(func(key K, item *Item[V]) {
+		evicted = append(evicted, eviction[K, V]{key, item.object, EvictFlushed})
+	})
+	c.lru.Flush()
+	c.mu.Unlock()
+	c.notify(evicted)
+}
+
+// OnEvicted registers a callback function which is invoked each time an entry is removed from the cache,
+// together with the reason of the removal. The callback is invoked outside of the cache lock.
+func (c *SyncLRU[K, V]) OnEvicted(fn func(key K, val V, reason EvictReason)) {
+	c.mu.Lock()
+	c.onEvicted = fn
+	c.mu.Unlock()
+}
+
+// notify records the evictions and invokes the eviction callback. It must be called without holding the lock.
+func (c *SyncLRU[K, V]) notify(evicted []eviction[K, V]) {
+	if len(evicted) == 0 {
+		return
+	}
+	for _, e := range evicted {
+		c.stats.evicted(e.reason, 1)
+	}
+
+	c.mu.Lock()
+	fn := c.onEvicted
+	c.mu.Unlock()
+
+	if fn != nil {
+		for _, e := range evicted {
+			fn(e.key, e.val, e.reason)
+		}
+	}
+}
+
+// now returns the current time of the cache clock in nanoseconds.
+func (c *SyncLRU[K, V]) now() int64 {
+	return c.clock.Now().UnixNano()
+}
+
+// Stats returns a snapshot of the cache statistics.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (c *SyncLRU[K, V]) Stats() Stats {
+	stats := c.stats.snapshot()
+
+	c.mu.Lock()
+	// The rejections are counted by the underlying LRU cache.
+	stats.Rejections = c.lru.Stats().Rejections
+	c.mu.Unlock()
+
+	return stats
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (c *SyncLRU[K, V]) ResetStats() {
+	c.stats.reset()
+
+	c.mu.Lock()
+	c.lru.ResetStats()
+	c.mu.Unlock()
+}
+
+// AsStore returns an adapter exposing the cache through the Store interface.
+// The values are stored with the default expiration time of the cache.
+func (c *SyncLRU[K, V]) AsStore() Store[K, V] {
+	return syncLRUStore[K, V]{c}
+}
+
+// syncLRUStore adapts the concurrency-safe LRU cache to the Store interface.
+type syncLRUStore[K comparable, V any] struct {
+	c *SyncLRU[K, V]
+}
+
+func (s syncLRUStore[K, V]) Get(key K) (V, bool) {
+	return s.c.Get(key)
+}
+
+func (s syncLRUStore[K, V]) Set(key K, val V) error {
+	s.c.Add(key, val)
+	return nil
+}
+
+func (s syncLRUStore[K, V]) SetWithTTL(key K, val V, d time.Duration) error {
+	s.c.AddWithTTL(key, val, d)
+	return nil
+}
+
+func (s syncLRUStore[K, V]) Delete(key K) bool {
+	_, removed := s.c.Remove(key)
+	return removed
+}
+
+func (s syncLRUStore[K, V]) Len() int {
+	return s.c.Count()
+}
+
+func (s syncLRUStore[K, V]) Clear() {
+	s.c.Flush()
+}
+
+func (s syncLRUStore[K, V]) Stats() Stats {
+	return s.c.Stats()
+}
+
+func (s syncLRUStore[K, V]) ResetStats() {
+	s.c.ResetStats()
+}
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncLRU_Basic(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewSyncLRU[string, int](0, NoExpiration)
+	assert.Error(err)
+
+	c, err := NewSyncLRU[string, int](2, NoExpiration, WithStats())
+	assert.NoError(err)
+
+	var evicted []EvictReason
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		evicted = append(evicted, reason)
+	})
+
+	assert.False(c.Add("foo", 1))
+	assert.False(c.Add("bar", 2))
+	assert.False(c.Add("foo", 3))
+	assert.Equal([]EvictReason{EvictReplaced}, evicted)
+
+	// Peek does not promote "bar", so it gets evicted next.
+	val, ok := c.Peek("bar")
+	assert.True(ok)
+	assert.Equal(2, val)
+	assert.True(c.Add("baz", 4))
+	assert.False(c.Contains("bar"))
+	assert.Equal(EvictCapacity, evicted[len(evicted)-1])
+
+	val, ok = c.Get("foo")
+	assert.True(ok)
+	assert.Equal(3, val)
+	_, ok = c.Get("bar")
+	assert.False(ok)
+
+	val, ok = c.Remove("foo")
+	assert.True(ok)
+	assert.Equal(3, val)
+	assert.Equal(EvictDeleted, evicted[len(evicted)-1])
+
+	stats := c.Stats()
+	assert.Equal(uint64(1), stats.Hits)
+	assert.Equal(uint64(1), stats.Misses)
+	assert.Equal(uint64(3), stats.Sets)
+	assert.Equal(uint64(1), stats.Updates)
+
+	c.Flush()
+	assert.Equal(0, c.Count())
+	assert.Equal(EvictFlushed, evicted[len(evicted)-1])
+}
+
+func TestSyncLRU_TTL(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := NewSyncLRU[string, int](10, 20*time.Millisecond)
+	assert.NoError(err)
+
+	var expired int
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		if reason == EvictExpired {
+			expired++
+		}
+	})
+
+	c.Add("foo", 1)
+	c.Add("bar", 2)
+	c.AddWithTTL("baz", 3, NoExpiration)
+	c.AddWithTTL("qux", 4, time.Minute)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.False(c.Contains("foo"))
+	_, ok := c.Peek("bar")
+	assert.False(ok)
+	assert.True(c.Contains("baz"))
+	assert.True(c.Contains("qux"))
+	assert.Equal(4, c.Count())
+
+	_, ok = c.Get("foo")
+	assert.False(ok)
+	assert.Equal(1, expired)
+	assert.Equal(1, c.DeleteExpired())
+	assert.Equal(2, expired)
+	assert.Equal(2, c.Count())
+}
+
+func TestSyncLRU_Clock(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+	c, err := NewSyncLRU[string, int](2, time.Second, WithClock(clk))
+	assert.NoError(err)
+
+	c.Add("foo", 1)
+	c.AddWithTTL("bar", 2, NoExpiration)
+	clk.Advance(2 * time.Second)
+
+	_, ok := c.Get("foo")
+	assert.False(ok)
+	val, ok := c.Get("bar")
+	assert.True(ok)
+	assert.Equal(2, val)
+}
+
+func TestSyncLRU_TinyLFU(t *testing.T) {
+	assert := assert.New(t)
+
+	c, err := NewSyncLRU[int, int](10, time.Second, WithTinyLFU(), WithStats(), WithClock(clock.NewFake(time.Now())))
+	assert.NoError(err)
+
+	var rejected []int
+	c.OnEvicted(func(key int, val int, reason EvictReason) {
+		if reason == EvictCapacity {
+			rejected = append(rejected, key)
+		}
+	})
+
+	for i := 0; i < 10; i++ {
+		c.Add(i, i)
+		c.Get(i)
+	}
+
+	// The one-hit keys pushed out of the admission window are not admitted.
+	for i := 100; i < 105; i++ {
+		assert.True(c.Add(i, i))
+	}
+	assert.Equal([]int{9, 100, 101, 102, 103}, rejected)
+	assert.Equal(10, c.Count())
+
+	stats := c.Stats()
+	assert.Equal(uint64(5), stats.Rejections)
+	assert.Equal(uint64(15), stats.Sets)
+
+	// The entries held by the admission window are flushed too.
+	c.Flush()
+	assert.Equal(0, c.Count())
+	assert.False(c.Contains(104))
+	assert.Equal(uint64(15), c.Stats().Evictions)
+
+	c.ResetStats()
+	assert.Equal(Stats{}, c.Stats())
+}
+
+func TestSyncLRU_Resize(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewSyncLRU[int, int](5, NoExpiration)
+	for i := 0; i < 5; i++ {
+		c.Add(i, i)
+	}
+	c.Get(0)
+
+	var keys []int
+	c.OnEvicted(func(key int, val int, reason EvictReason) {
+		assert.Equal(EvictCapacity, reason)
+		keys = append(keys, key)
+	})
+
+	_, err := c.Resize(-1)
+	assert.Error(err)
+
+	n, err := c.Resize(2)
+	assert.NoError(err)
+	assert.Equal(3, n)
+	assert.Equal([]int{1, 2, 3}, keys)
+	assert.True(c.Contains(0))
+	assert.True(c.Contains(4))
+
+	n, err = c.Resize(4)
+	assert.NoError(err)
+	assert.Equal(0, n)
+	c.Add(5, 5)
+	c.Add(6, 6)
+	assert.Equal(4, c.Count())
+}
+
+func TestSyncLRU_Concurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewSyncLRU[string, int](100, time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := strconv.Itoa(j % 200)
+				c.Add(key, i)
+				c.Get(key)
+				c.Peek(key)
+				if j%100 == 0 {
+					c.Resize(50 + j%100)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(c.Count(), 100)
+}
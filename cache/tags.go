@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SetWithTags inserts a new item into the cache and associates it with the provided tags.
+// All the items sharing a tag can be removed at once with the InvalidateTag method.
+// The tags are kept until the item is removed, the Update method carrying them over to the new value.
+// In case an item with the specified key already exists in the cache it will return an error.
+func (c *Cache[K, V]) SetWithTags(key K, val V, d time.Duration, tags ...string) error {
+	item, err := c.get(key)
+	if item != nil && err == nil {
+		return fmt.Errorf("item with key '%v' already exists. Use the Update method", key)
+	}
+
+	// The tags are copied, since the caller might reuse the slice passed in.
+	return c.add(key, val, d, append([]string(nil), tags...)...)
+}
+
+// InvalidateTag removes all the items associated with the tag and returns the number of removed items.
+func (c *Cache[K, V]) InvalidateTag(tag string) int {
+	var evicted []eviction[K, V]
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0
+	}
+	for key := range c.tagIndex[tag] {
+		if item, err := c.delete(key); err == nil {
+			evicted = append(evicted, eviction[K, V]{key, item.object, EvictDeleted})
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return len(evicted)
+}
+
+// DeleteFunc removes all the items for which the provided function returns true
+// and returns the number of removed items. The function is invoked while holding
+// the cache lock, which means it must not access the cache.
+func (c *Cache[K, V]) DeleteFunc(fn func(key K, val V) bool) int {
+	var evicted []eviction[K, V]
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0
+	}
+	for key, item := range c.items {
+		if fn(key, item.object) {
+			c.delete(key)
+			evicted = append(evicted, eviction[K, V]{key, item.object, EvictDeleted})
+		}
+	}
+	c.mu.Unlock()
+	c.notify(evicted)
+
+	return len(evicted)
+}
+
+// DeletePrefix removes all the items having a key starting with the provided prefix
+// and returns the number of removed items.
+func DeletePrefix[K ~string, V any](c *Cache[K, V], prefix K) int {
+	return c.DeleteFunc(func(key K, _ V) bool {
+		return strings.HasPrefix(string(key), string(prefix))
+	})
+}
+
+// tag adds the key into the index of each tag. The lock should be held by the caller.
+func (c *cache[K, V]) tag(key K, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+	if c.tagIndex == nil {
+		c.tagIndex = make(map[string]map[K]struct{})
+	}
+	for _, t := range tags {
+		keys, ok := c.tagIndex[t]
+		if !ok {
+			keys = make(map[K]struct{})
+			c.tagIndex[t] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// untag removes the key from the index of each tag. The lock should be held by the caller.
+func (c *cache[K, V]) untag(key K, tags []string) {
+	for _, t := range tags {
+		if keys, ok := c.tagIndex[t]; ok {
+			delete(keys, key)
+			if len(keys) == 0 {
+				delete(c.tagIndex, t)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_InvalidateTag(t *testing.T) {
+	assert := assert.New(t)
+
+	var evicted []string
+
+	c := New[string, int](NoExpiration, 0)
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		if reason == EvictDeleted {
+			evicted = append(evicted, key)
+		}
+	})
+
+	assert.NoError(c.SetWithTags("t1:a", 1, DefaultExpiration, "tenant1"))
+	assert.NoError(c.SetWithTags("t1:b", 2, DefaultExpiration, "tenant1", "premium"))
+	assert.NoError(c.SetWithTags("t2:a", 3, DefaultExpiration, "tenant2", "premium"))
+	assert.NoError(c.Set("other", 4, DefaultExpiration))
+	assert.Error(c.SetWithTags("t1:a", 1, DefaultExpiration, "tenant2"))
+
+	n := c.InvalidateTag("tenant1")
+	assert.Equal(2, n)
+	assert.ElementsMatch([]string{"t1:a", "t1:b"}, evicted)
+	assert.Equal(2, c.Count())
+
+	// The removed items are dropped from the index of the other tags too.
+	assert.Equal(1, c.InvalidateTag("premium"))
+	assert.Equal(0, c.InvalidateTag("tenant2"))
+	assert.Equal(0, c.InvalidateTag("missing"))
+	assert.Empty(c.tagIndex)
+
+	// The tags are kept once the item is replaced with Update.
+	c.SetWithTags("x", 1, DefaultExpiration, "tag")
+	c.Update("x", 2, DefaultExpiration)
+	assert.Equal(1, c.InvalidateTag("tag"))
+
+	// Reusing the tags slice doesn't affect the stored item.
+	tags := []string{"tag"}
+	c.SetWithTags("z", 1, DefaultExpiration, tags...)
+	tags[0] = "other"
+	assert.Equal(0, c.InvalidateTag("other"))
+	assert.Equal(1, c.InvalidateTag("tag"))
+
+	// The tags are preserved on save and load.
+	var buf bytes.Buffer
+	c.SetWithTags("y", 1, DefaultExpiration, "tag")
+	assert.NoError(c.Save(&buf))
+	c.Flush()
+	assert.NoError(c.Load(&buf))
+	assert.Equal(1, c.InvalidateTag("tag"))
+}
+
+func TestCache_DeleteFunc(t *testing.T) {
+	assert := assert.New(t)
+
+	c := New[string, int](NoExpiration, 0)
+	c.MapToCache(map[string]int{
+		"user:1":  1,
+		"user:2":  2,
+		"order:1": 3,
+		"order:2": 4,
+	}, DefaultExpiration)
+
+	n := c.DeleteFunc(func(key string, val int) bool {
+		return val%2 == 0
+	})
+	assert.Equal(2, n)
+	assert.Equal(2, c.Count())
+
+	n = DeletePrefix(c, "user:")
+	assert.Equal(1, n)
+	_, err := c.Get("order:1")
+	assert.NoError(err)
+	assert.Equal(1, c.Count())
+
+	s, _ := NewSharded[string, int](4, NoExpiration, 0)
+	s.SetWithTags("a", 1, DefaultExpiration, "tag")
+	s.SetWithTags("b", 2, DefaultExpiration, "tag")
+	s.Set("c", 3, DefaultExpiration)
+	assert.Equal(2, s.InvalidateTag("tag"))
+	assert.Equal(1, s.DeleteFunc(func(string, int) bool { return true }))
+	assert.Equal(0, s.Count())
+}
@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tiered is a two-tier cache composed of a size bounded LRU cache in front of a TTL based cache.
+// The hot tier holds the most recently used items, while the cold tier holds all the items.
+// Every write goes through both tiers, the items found only in the cold tier are promoted
+// into the hot tier on read and the deletions are applied on both tiers.
+// The reads served from the hot tier are still recorded in the statistics and in the access order
+// of the cold tier. If the cold tier uses sliding expiration, the reads always go through the cold tier,
+// since each of them renews the expiration time of the item.
+type Tiered[K comparable, V any] struct {
+	mu        sync.Mutex
+	hot       *LRUCache[K, *Item[V]]
+	cold      *Cache[K, V]
+	onEvicted func(K, V, EvictReason)
+}
+
+// NewTiered instantiates a two-tier cache. The hot tier can hold at most hotSize items.
+// The expiration time, the cleanup interval and the options are used for creating the cold tier,
+// which has the same semantics as the cache created with New.
+func NewTiered[K comparable, V any](hotSize int, expTime, cleanupTime time.Duration, opts ...Option) (*Tiered[K, V], error) {
+	hot, err := NewLRU[K, *Item[V]](hotSize)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Tiered[K, V]{
+		hot:  hot,
+		cold: New[K, V](expTime, cleanupTime, opts...),
+	}
+	// The items removed from the cold tier (e.g. expired or evicted) are also removed from the hot tier.
+	t.cold.OnEvicted(t.evicted)
+
+	return t, nil
+}
+
+// evicted removes the item evicted from the cold tier from the hot tier too.
+// The replaced items are removed as well, since the hot tier holds a copy of the former value.
+func (t *Tiered[K, V]) evicted(key K, val V, reason EvictReason) {
+	t.mu.Lock()
+	t.hot.Remove(key)
+	fn := t.onEvicted
+	t.mu.Unlock()
+
+	if fn != nil {
+		fn(key, val, reason)
+	}
+}
+
+// Set inserts a new item into both tiers. In case an item with the specified key
+// already exists it will return an error.
+func (t *Tiered[K, V]) Set(key K, val V, d time.Duration) error {
+	if err := t.cold.Set(key, val, d); err != nil {
+		return err
+	}
+	t.promote(key)
+
+	return nil
+}
+
+// SetDefault adds a new item into both tiers with the default expiration time.
+func (t *Tiered[K, V]) SetDefault(key K, val V) error {
+	return t.Set(key, val, DefaultExpiration)
+}
+
+// Update replaces an item with the new value in both tiers.
+func (t *Tiered[K, V]) Update(key K, val V, d time.Duration) error {
+	if err := t.cold.Update(key, val, d); err != nil {
+		return err
+	}
+	t.promote(key)
+
+	return nil
+}
+
+// Get returns an item defined by its key, looking it up first in the hot tier and then in the cold tier.
+// The item found in the cold tier is promoted into the hot tier. If the item is expired an error is returned.
+func (t *Tiered[K, V]) Get(key K) (*Item[V], error) {
+	if !t.cold.sliding {
+		t.mu.Lock()
+		item, ok := t.hot.Get(key)
+		if ok && item.expired(t.cold.now()) {
+			t.hot.Remove(key)
+			ok = false
+		}
+		t.mu.Unlock()
+
+		if ok {
+			t.cold.stats.hit()
+			t.cold.accessed(key)
+			return item, nil
+		}
+	}
+
+	if _, err := t.cold.Get(key); err != nil {
+		return nil, err
+	}
+	if item := t.promote(key); item != nil {
+		return item, nil
+	}
+
+	return nil, fmt.Errorf("item with key '%v' not found", key)
+}
+
+// promote copies the item from the cold tier into the hot tier and returns it.
+// The copy is made while holding the lock of the hot tier, which serializes it against
+// the removal of the hot items triggered by the cold tier writes. This way a stale copy
+// added into the hot tier is always removed by the eviction callback of the write which replaced it.
+func (t *Tiered[K, V]) promote(key K) *Item[V] {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.cold.copyItem(key)
+	if !ok {
+		return nil
+	}
+	t.hot.Add(key, item)
+
+	return item
+}
+
+// Delete removes an item from both tiers.
+func (t *Tiered[K, V]) Delete(key K) error {
+	err := t.cold.Delete(key)
+
+	t.mu.Lock()
+	t.hot.Remove(key)
+	t.mu.Unlock()
+
+	return err
+}
+
+// Flush removes all the items from both tiers.
+func (t *Tiered[K, V]) Flush() {
+	t.cold.Flush()
+
+	t.mu.Lock()
+	t.hot.Flush()
+	t.mu.Unlock()
+}
+
+// Count returns the number of items, which is the number of items stored in the cold tier.
+func (t *Tiered[K, V]) Count() int {
+	return t.cold.Count()
+}
+
+// HotCount returns the number of items stored in the hot tier.
+func (t *Tiered[K, V]) HotCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.hot.Count()
+}
+
+// OnEvicted registers a callback function which is invoked each time an item is removed from the cold tier.
+// See Cache.OnEvicted for more details.
+func (t *Tiered[K, V]) OnEvicted(fn func(key K, val V, reason EvictReason)) {
+	t.mu.Lock()
+	t.onEvicted = fn
+	t.mu.Unlock()
+}
+
+// Close closes the cold tier and flushes the hot tier.
+func (t *Tiered[K, V]) Close() error {
+	t.mu.Lock()
+	t.hot.Flush()
+	t.mu.Unlock()
+
+	return t.cold.Close()
+}
@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/esimov/gogu/clock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTiered(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := NewTiered[string, int](0, NoExpiration, 0)
+	assert.Error(err)
+
+	c, err := NewTiered[string, int](2, NoExpiration, 0)
+	assert.NoError(err)
+	defer c.Close()
+
+	// Every write goes through both tiers.
+	assert.NoError(c.Set("a", 1, DefaultExpiration))
+	assert.NoError(c.Set("b", 2, DefaultExpiration))
+	assert.NoError(c.Set("c", 3, DefaultExpiration))
+	assert.Error(c.Set("a", 1, DefaultExpiration))
+	assert.Equal(3, c.Count())
+	assert.Equal(2, c.HotCount())
+
+	_, ok := c.hot.Get("a")
+	assert.False(ok)
+
+	// The item found only in the cold tier is promoted into the hot tier.
+	item, err := c.Get("a")
+	assert.NoError(err)
+	assert.Equal(1, item.Val())
+	hot, ok := c.hot.Get("a")
+	assert.True(ok)
+	assert.Equal(1, hot.Val())
+	_, ok = c.hot.Get("b")
+	assert.False(ok)
+
+	assert.NoError(c.Update("a", 10, DefaultExpiration))
+	item, _ = c.Get("a")
+	assert.Equal(10, item.Val())
+	cold, _ := c.cold.Get("a")
+	assert.Equal(10, cold.Val())
+
+	// The deletions are applied on both tiers.
+	assert.NoError(c.Delete("a"))
+	_, err = c.Get("a")
+	assert.Error(err)
+	_, ok = c.hot.Get("a")
+	assert.False(ok)
+	assert.Error(c.Delete("a"))
+
+	_, err = c.Get("x")
+	assert.Error(err)
+
+	c.Flush()
+	assert.Equal(0, c.Count())
+	assert.Equal(0, c.HotCount())
+}
+
+func TestTiered_Expiration(t *testing.T) {
+	assert := assert.New(t)
+
+	var evicted []string
+
+	c, _ := NewTiered[string, int](10, NoExpiration, 0)
+	c.OnEvicted(func(key string, val int, reason EvictReason) {
+		evicted = append(evicted, key)
+	})
+
+	c.Set("a", 1, 5*time.Millisecond)
+	c.Set("b", 2, time.Minute)
+	<-time.After(10 * time.Millisecond)
+
+	// The expired items are not served from the hot tier.
+	_, err := c.Get("a")
+	assert.Error(err)
+	assert.Equal(1, c.HotCount())
+
+	// The items removed from the cold tier are removed from the hot tier too.
+	c.SetDefault("c", 3)
+	c.cold.Delete("c")
+	assert.Equal(1, c.HotCount())
+	_, err = c.Get("c")
+	assert.Error(err)
+
+	c.cold.DeleteExpired()
+	assert.Equal([]string{"c", "a"}, evicted)
+
+	assert.NoError(c.Close())
+	assert.ErrorIs(c.Set("d", 1, DefaultExpiration), ErrClosed)
+}
+
+func TestTiered_Cold(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	c, _ := NewTiered[string, int](10, NoExpiration, 0, WithStats(), WithSlidingExpiration(), WithClock(clk))
+	defer c.Close()
+
+	c.Set("a", 1, time.Minute)
+	_, ok := c.hot.Get("a")
+	assert.True(ok)
+
+	// The hot tier reads renew the expiration time of the cold items and are recorded in the statistics.
+	for i := 0; i < 3; i++ {
+		clk.Advance(40 * time.Second)
+		item, err := c.Get("a")
+		assert.NoError(err)
+		assert.Equal(1, item.Val())
+	}
+	assert.Equal(uint64(3), c.cold.Stats().Hits)
+
+	// The replaced items are removed from the hot tier.
+	c.cold.Update("a", 2, DefaultExpiration)
+	_, ok = c.hot.Get("a")
+	assert.False(ok)
+	item, _ := c.Get("a")
+	assert.Equal(2, item.Val())
+
+	c2, _ := NewTiered[string, int](10, NoExpiration, 0, WithStats())
+	defer c2.Close()
+	c2.Set("a", 1, DefaultExpiration)
+	c2.Get("a")
+	c2.Get("b")
+	assert.Equal(uint64(1), c2.cold.Stats().Hits)
+	assert.Equal(uint64(1), c2.cold.Stats().Misses)
+}
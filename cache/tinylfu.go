@@ -0,0 +1,360 @@
+package cache
+
+import "github.com/esimov/gogu/internal/keys"
+
+const (
+	// sketchDepth is the number of rows of the count-min sketch.
+	sketchDepth = 4
+	// sketchMaxCount is the value at which the sketch counters saturate.
+	sketchMaxCount = 15
+	// sketchSampleFactor multiplied with the cache size gives the number of
+	// increments after which the sketch counters are aged.
+	sketchSampleFactor = 10
+
+	// windowRatio is the ratio of the cache size reserved for the admission window.
+	windowRatio = 0.01
+)
+
+// sketchSeeds are used for deriving an independent hash for each row of the sketch.
+var sketchSeeds = [sketchDepth]uint64{
+	0xc3a5c85c97cb3127, 0xb492b66fbe98f273, 0x9ae16a3b2f90404f, 0xcbf29ce484222325,
+}
+
+// countMinSketch estimates the access frequency of the keys using a fixed amount of memory.
+// The counters are halved periodically by the admission filter, so that the old accesses weigh less than the recent ones.
+type countMinSketch struct {
+	counters [sketchDepth][]uint8
+	mask     uint64
+}
+
+func newCountMinSketch(size int) *countMinSketch {
+	width := 16
+	for width < 4*size {
+		width <<= 1
+	}
+
+	s := &countMinSketch{mask: uint64(width - 1)}
+	for i := range s.counters {
+		s.counters[i] = make([]uint8, width)
+	}
+	return s
+}
+
+// index returns the position of the hash in the provided row.
+// The hash is combined with the seed of the row and mixed using the finalizer of MurmurHash3.
+func (s *countMinSketch) index(hash uint64, row int) uint64 {
+	h := hash + sketchSeeds[row]
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h & s.mask
+}
+
+// increment increments the counters of the hash.
+func (s *countMinSketch) increment(hash uint64) {
+	for i := range s.counters {
+		if idx := s.index(hash, i); s.counters[i][idx] < sketchMaxCount {
+			s.counters[i][idx]++
+		}
+	}
+}
+
+// estimate returns the estimated access frequency of the hash, which is the minimum of its counters.
+func (s *countMinSketch) estimate(hash uint64) uint8 {
+	est := uint8(sketchMaxCount)
+	for i := range s.counters {
+		if c := s.counters[i][s.index(hash, i)]; c < est {
+			est = c
+		}
+	}
+	return est
+}
+
+// age halves all the counters.
+func (s *countMinSketch) age() {
+	for i := range s.counters {
+		for j := range s.counters[i] {
+			s.counters[i][j] >>= 1
+		}
+	}
+}
+
+// doorkeeper is a bloom filter recording the keys accessed since the last aging of the sketch.
+// Only the subsequent accesses of a key are counted by the sketch, which keeps the keys
+// accessed only once from taking up the sketch counters.
+type doorkeeper struct {
+	bits []uint64
+	mask uint64
+}
+
+func newDoorkeeper(width int) *doorkeeper {
+	return &doorkeeper{
+		bits: make([]uint64, (width+63)/64),
+		mask: uint64(width - 1),
+	}
+}
+
+// index returns the positions of the hash in the filter, derived from its two halves.
+func (d *doorkeeper) index(hash uint64) (uint64, uint64) {
+	return hash & d.mask, (hash >> 32) & d.mask
+}
+
+// contains reports whether the hash has been added to the filter.
+func (d *doorkeeper) contains(hash uint64) bool {
+	i, j := d.index(hash)
+	return d.bits[i/64]&(1<<(i%64)) != 0 && d.bits[j/64]&(1<<(j%64)) != 0
+}
+
+// add adds the hash to the filter and reports whether it was already present.
+func (d *doorkeeper) add(hash uint64) bool {
+	if d.contains(hash) {
+		return true
+	}
+	i, j := d.index(hash)
+	d.bits[i/64] |= 1 << (i % 64)
+	d.bits[j/64] |= 1 << (j % 64)
+	return false
+}
+
+// reset clears the filter.
+func (d *doorkeeper) reset() {
+	for i := range d.bits {
+		d.bits[i] = 0
+	}
+}
+
+// tinyLFU is the admission filter of the fixed size caches, implementing the frequency based admission policy
+// of W-TinyLFU. The first access of a key since the last aging is recorded by the doorkeeper,
+// while the subsequent ones are counted by the count-min sketch.
+// A nil filter is valid and means that all the new keys are admitted.
+type tinyLFU[K comparable] struct {
+	sketch     *countMinSketch
+	doorkeeper *doorkeeper
+	size       int
+	additions  int
+	sample     int
+}
+
+// newTinyLFU returns a new admission filter only if it's enabled.
+func newTinyLFU[K comparable](enabled bool, size int) *tinyLFU[K] {
+	if !enabled {
+		return nil
+	}
+
+	sketch := newCountMinSketch(size)
+	return &tinyLFU[K]{
+		sketch:     sketch,
+		doorkeeper: newDoorkeeper(int(sketch.mask + 1)),
+		size:       size,
+		sample:     size * sketchSampleFactor,
+	}
+}
+
+// record registers an access of the key, aging the recorded accesses once the sample size is reached.
+func (f *tinyLFU[K]) record(key K) {
+	if f == nil {
+		return
+	}
+
+	if hash := keys.Hash(key); f.doorkeeper.add(hash) {
+		f.sketch.increment(hash)
+	}
+	if f.additions++; f.additions >= f.sample {
+		f.sketch.age()
+		f.doorkeeper.reset()
+		f.additions /= 2
+	}
+}
+
+// estimate returns the estimated access frequency of the key.
+func (f *tinyLFU[K]) estimate(key K) uint8 {
+	hash := keys.Hash(key)
+	est := f.sketch.estimate(hash)
+	if f.doorkeeper.contains(hash) {
+		est++
+	}
+	return est
+}
+
+// admit reports whether the candidate key should replace the victim key,
+// which happens only if the candidate is estimated to be more frequently used.
+func (f *tinyLFU[K]) admit(candidate, victim K) bool {
+	if f == nil {
+		return true
+	}
+	return f.estimate(candidate) > f.estimate(victim)
+}
+
+// reset drops all the recorded accesses.
+func (f *tinyLFU[K]) reset() {
+	if f != nil {
+		f.sketch = newCountMinSketch(f.size)
+		f.doorkeeper.reset()
+		f.additions = 0
+	}
+}
+
+// admissionWindow is the window cache of W-TinyLFU, a small LRU cache placed in front of the main cache.
+// The new keys are always admitted into the window, which gives them the chance to build up their
+// access frequency, and only the keys evicted from the window compete with the victims of the main cache.
+// A nil window is valid and means that the new keys compete directly with the victims of the main cache.
+type admissionWindow[K comparable, V any] struct {
+	lru  *lruMap[K, V]
+	size int
+}
+
+// newAdmissionWindow returns a new admission window only if the admission filter is enabled
+// and the cache is large enough for holding both the window and the main cache.
+func newAdmissionWindow[K comparable, V any](enabled bool, size int) *admissionWindow[K, V] {
+	if !enabled || size < 2 {
+		return nil
+	}
+	return &admissionWindow[K, V]{lru: newLRUMap[K, V](), size: windowSize(size)}
+}
+
+// windowSize returns the size of the admission window of a cache holding size values.
+// A cache holding a single value has no room for the window.
+func windowSize(size int) int {
+	if size < 2 {
+		return 0
+	}
+	return atLeastOne(float64(size) * windowRatio)
+}
+
+// capacity returns the number of values the window can hold.
+func (w *admissionWindow[K, V]) capacity() int {
+	if w == nil {
+		return 0
+	}
+	return w.size
+}
+
+// push adds a new value to the window. Once the window is full, its least recently used value is evicted
+// and returned as the candidate for the main cache. Without a window, the new value is the candidate itself.
+func (w *admissionWindow[K, V]) push(key K, value V) (candidate K, candidateValue V, ok bool) {
+	if w == nil {
+		return key, value, true
+	}
+
+	w.lru.pushFront(key, value)
+	if w.lru.len() > w.size {
+		return w.lru.removeOldest()
+	}
+	return
+}
+
+// rejected returns the candidate rejected by the admission filter as the value evicted from the cache,
+// since it was pushed out of the window. Without a window, the candidate is the new value, which was never stored.
+func (w *admissionWindow[K, V]) rejected(key K, value V) (rejectedKey K, rejectedValue V, removed bool) {
+	if w == nil {
+		return
+	}
+	return key, value, true
+}
+
+// resize changes the capacity of the window for a cache holding size values.
+// The values not fitting into the window anymore are evicted, and fn is invoked for each of them.
+func (w *admissionWindow[K, V]) resize(size int, fn func(K, V)) {
+	if w == nil {
+		return
+	}
+	w.size = windowSize(size)
+	for w.lru.len() > w.size {
+		key, value, _ := w.lru.removeOldest()
+		fn(key, value)
+	}
+}
+
+// update replaces the value of a key held by the window and marks it as recently used.
+// It reports whether the key was found.
+func (w *admissionWindow[K, V]) update(key K, value V) bool {
+	if w == nil {
+		return false
+	}
+	if nd, ok := w.lru.get(key); ok {
+		nd.value = value
+		w.lru.moveFront(nd)
+		return true
+	}
+	return false
+}
+
+// get returns the value of a key held by the window and marks it as recently used.
+func (w *admissionWindow[K, V]) get(key K) (value V, ok bool) {
+	if w == nil {
+		return
+	}
+	if nd, ok := w.lru.get(key); ok {
+		w.lru.moveFront(nd)
+		return nd.value, true
+	}
+	return
+}
+
+// peek returns the value of a key held by the window, without marking it as recently used.
+func (w *admissionWindow[K, V]) peek(key K) (value V, ok bool) {
+	if w == nil {
+		return
+	}
+	if nd, ok := w.lru.get(key); ok {
+		return nd.value, true
+	}
+	return
+}
+
+// contains reports whether the key is held by the window.
+func (w *admissionWindow[K, V]) contains(key K) bool {
+	return w != nil && w.lru.contains(key)
+}
+
+// oldest returns the least recently used key of the window.
+func (w *admissionWindow[K, V]) oldest() (key K, ok bool) {
+	if w == nil {
+		return
+	}
+	return w.lru.oldest()
+}
+
+// youngest returns the most recently used key of the window.
+func (w *admissionWindow[K, V]) youngest() (key K, ok bool) {
+	if w == nil {
+		return
+	}
+	if nd := w.lru.list.first(); nd != &w.lru.list.root {
+		return nd.key, true
+	}
+	return
+}
+
+// remove removes the key from the window.
+func (w *admissionWindow[K, V]) remove(key K) (value V, removed bool) {
+	if w == nil {
+		return
+	}
+	return w.lru.remove(key)
+}
+
+// removeOldest removes the least recently used value of the window.
+func (w *admissionWindow[K, V]) removeOldest() (key K, value V, removed bool) {
+	if w == nil {
+		return
+	}
+	return w.lru.removeOldest()
+}
+
+// len returns the number of values held by the window.
+func (w *admissionWindow[K, V]) len() int {
+	if w == nil {
+		return 0
+	}
+	return w.lru.len()
+}
+
+// reset removes all the values from the window.
+func (w *admissionWindow[K, V]) reset() {
+	if w != nil {
+		w.lru.reset()
+	}
+}
@@ -0,0 +1,178 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/esimov/gogu/internal/keys"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountMinSketch(t *testing.T) {
+	assert := assert.New(t)
+
+	s := newCountMinSketch(100)
+	for i := 0; i < 10; i++ {
+		s.increment(1)
+	}
+	s.increment(2)
+	assert.Equal(uint8(10), s.estimate(1))
+	assert.Equal(uint8(1), s.estimate(2))
+	assert.Equal(uint8(0), s.estimate(3))
+
+	// The counters saturate.
+	for i := 0; i < 100; i++ {
+		s.increment(1)
+	}
+	assert.Equal(uint8(sketchMaxCount), s.estimate(1))
+
+	// The aging halves the counters.
+	s.age()
+	assert.Equal(uint8(sketchMaxCount/2), s.estimate(1))
+	assert.Equal(uint8(0), s.estimate(2))
+}
+
+func TestTinyLFU_Doorkeeper(t *testing.T) {
+	assert := assert.New(t)
+
+	f := newTinyLFU[string](true, 100)
+
+	// The first access is recorded only by the doorkeeper, the subsequent ones by the sketch.
+	f.record("foo")
+	assert.Equal(uint8(0), f.sketch.estimate(keys.Hash("foo")))
+	assert.Equal(uint8(1), f.estimate("foo"))
+	f.record("foo")
+	assert.Equal(uint8(1), f.sketch.estimate(keys.Hash("foo")))
+	assert.Equal(uint8(2), f.estimate("foo"))
+	assert.Equal(uint8(0), f.estimate("bar"))
+
+	// The doorkeeper is cleared and the sketch is aged once the sample size is reached.
+	for i := 0; f.doorkeeper.contains(keys.Hash("foo")); i++ {
+		f.record(strconv.Itoa(i))
+	}
+	assert.Equal(f.sample/2, f.additions)
+	assert.Equal(uint8(0), f.estimate("foo"))
+}
+
+func TestTinyLFU_Admission(t *testing.T) {
+	for name, c := range boundedCaches[int, int](10, WithTinyLFU(), WithStats()) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			for i := 0; i < 10; i++ {
+				c.Add(i, i)
+			}
+			for n := 0; n < 3; n++ {
+				for i := 0; i < 10; i++ {
+					c.Get(i)
+				}
+			}
+
+			// The new keys enter the admission window, while the keys pushed out of it are rejected,
+			// since they are not more frequently used than the keys from the main cache.
+			prev := 9
+			for i := 100; i < 120; i++ {
+				key, _, removed := c.Add(i, i)
+				assert.True(removed)
+				assert.Equal(prev, key)
+				assert.False(c.Contains(prev))
+				assert.True(c.Contains(i))
+				prev = i
+			}
+			for i := 0; i < 9; i++ {
+				assert.True(c.Contains(i))
+			}
+			assert.Equal(uint64(20), c.Stats().Rejections)
+			assert.Equal(10, c.Count())
+
+			// A key becoming more frequently used than the victim is admitted, once it's pushed out of the window.
+			for n := 0; n < 5; n++ {
+				c.Get(1000)
+			}
+			key, _, removed := c.Add(1000, 1000)
+			assert.True(removed)
+			assert.Equal(119, key)
+
+			key, _, removed = c.Add(2000, 2000)
+			assert.True(removed)
+			assert.Less(key, 9)
+			assert.True(c.Contains(1000))
+			assert.True(c.Contains(2000))
+			assert.Equal(10, c.Count())
+			assert.Equal(uint64(21), c.Stats().Rejections)
+		})
+	}
+}
+
+func TestTinyLFU_Window(t *testing.T) {
+	for name, c := range boundedCaches[int, int](10, WithTinyLFU(), WithStats()) {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+
+			// The last added key is held by the admission window.
+			for i := 0; i < 10; i++ {
+				c.Add(i, i)
+			}
+			assert.Equal(10, c.Count())
+
+			v, ok := c.Peek(9)
+			assert.True(ok)
+			assert.Equal(9, v)
+			v, ok = c.Get(9)
+			assert.True(ok)
+			assert.Equal(9, v)
+
+			_, _, removed := c.Add(9, 90)
+			assert.False(removed)
+			v, _ = c.Peek(9)
+			assert.Equal(90, v)
+			assert.Equal(uint64(1), c.Stats().Updates)
+
+			v, ok = c.Remove(9)
+			assert.True(ok)
+			assert.Equal(90, v)
+			assert.False(c.Contains(9))
+			assert.Equal(9, c.Count())
+
+			c.Add(9, 9)
+			c.Flush()
+			assert.Equal(0, c.Count())
+			assert.False(c.Contains(9))
+			assert.Equal(uint64(11), c.Stats().Evictions)
+		})
+	}
+}
+
+func TestTinyLFU_SingleValue(t *testing.T) {
+	assert := assert.New(t)
+
+	// A cache holding a single value has no admission window, so the new values compete directly for the cache.
+	c, _ := NewLRU[int, int](1, WithTinyLFU(), WithStats())
+	c.Add(1, 1)
+	c.Get(1)
+
+	_, _, removed := c.Add(2, 2)
+	assert.False(removed)
+	assert.False(c.Contains(2))
+	assert.True(c.Contains(1))
+	assert.Equal(uint64(1), c.Stats().Rejections)
+}
+
+func TestTinyLFU_Disabled(t *testing.T) {
+	assert := assert.New(t)
+
+	var f *tinyLFU[string]
+	f.record("foo")
+	f.reset()
+	assert.True(f.admit("foo", "bar"))
+	assert.Nil(newTinyLFU[string](false, 10))
+
+	var w *admissionWindow[string, int]
+	key, val, ok := w.push("foo", 1)
+	assert.True(ok)
+	assert.Equal("foo", key)
+	assert.Equal(1, val)
+	assert.Equal(0, w.len())
+	assert.Nil(newAdmissionWindow[string, int](false, 10))
+	assert.Nil(newAdmissionWindow[string, int](true, 1))
+}
@@ -0,0 +1,229 @@
+package cache
+
+const (
+	// twoQueueRecentRatio is the ratio of the cache size reserved for the recently added values.
+	twoQueueRecentRatio = 0.25
+	// twoQueueGhostRatio is the ratio of the cache size used for tracking the keys evicted from the recent queue.
+	twoQueueGhostRatio = 0.5
+)
+
+// TwoQueueCache implements a fixed size 2Q cache. The values added for the first time are kept in a recent queue,
+// and are promoted into a frequent queue only when they are accessed again. The keys evicted from the recent queue
+// are remembered in a ghost queue, so that the values added again shortly after are promoted directly.
+// This makes the cache resistant to the scans, which are evicting only the values from the recent queue.
+type TwoQueueCache[K comparable, V any] struct {
+	recent     *lruMap[K, V]
+	frequent   *lruMap[K, V]
+	ghost      *lruMap[K, struct{}]
+	size       int
+	recentSize int
+	ghostSize  int
+	stats      *statsCounter
+	admission  *tinyLFU[K]
+	window     *admissionWindow[K, V]
+}
+
+// NewTwoQueue initializes a new 2Q cache. The WithStats option enables collecting the cache statistics
+// and the WithTinyLFU option enables the W-TinyLFU admission policy.
+func NewTwoQueue[K comparable, V any](size int, opts ...Option) (*TwoQueueCache[K, V], error) {
+	if size <= 0 {
+		return nil, errInvalidSize
+	}
+
+	cfg := newConfig(opts)
+	window := newAdmissionWindow[K, V](cfg.tinyLFU, size)
+	admission := newTinyLFU[K](cfg.tinyLFU, size)
+	size -= window.capacity()
+
+	return &TwoQueueCache[K, V]{
+		recent:     newLRUMap[K, V](),
+		frequent:   newLRUMap[K, V](),
+		ghost:      newLRUMap[K, struct{}](),
+		size:       size,
+		recentSize: atLeastOne(float64(size) * twoQueueRecentRatio),
+		ghostSize:  atLeastOne(float64(size) * twoQueueGhostRatio),
+		stats:      newStatsCounter(cfg.stats),
+		admission:  admission,
+		window:     window,
+	}, nil
+}
+
+// atLeastOne truncates the value to an integer which is at least one.
+func atLeastOne(v float64) int {
+	if n := int(v); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Add adds a value to the cache. If a value is evicted, this value and the key for it is returned.
+// In case the W-TinyLFU admission policy is enabled, the new value enters the admission window and the value
+// pushed out of it might not be admitted into the cache, in which case it is returned as the evicted value.
+func (c *TwoQueueCache[K, V]) Add(key K, value V) (oldestKey K, oldestValue V, removed bool) {
+	c.admission.record(key)
+
+	if nd, ok := c.frequent.get(key); ok {
+		nd.value = value
+		c.frequent.moveFront(nd)
+		c.stats.update()
+		return
+	}
+	// The value was accessed again, so it's promoted into the frequent queue.
+	if _, ok := c.recent.remove(key); ok {
+		c.frequent.pushFront(key, value)
+		c.stats.update()
+		return
+	}
+	if c.window.update(key, value) {
+		c.stats.update()
+		return
+	}
+
+	// The new value enters the admission window and the value pushed out of it competes for the cache.
+	// Without an admission window, the new value itself competes for the cache.
+	key, value, ok := c.window.push(key, value)
+	if !ok {
+		return
+	}
+
+	ghostHit := c.ghost.contains(key)
+	if c.recent.len()+c.frequent.len() >= c.size {
+		if victim, ok := c.victim(ghostHit); ok && !c.admission.admit(key, victim) {
+			c.stats.reject()
+			return c.window.rejected(key, value)
+		}
+	}
+
+	if ghostHit {
+		c.ghost.remove(key)
+		oldestKey, oldestValue, removed = c.ensureSpace(true)
+		c.frequent.pushFront(key, value)
+	} else {
+		oldestKey, oldestValue, removed = c.ensureSpace(false)
+		c.recent.pushFront(key, value)
+	}
+	c.stats.set()
+
+	return
+}
+
+// ensureSpace evicts a value in case the cache is full. The value is evicted from the recent queue
+// if it exceeds its target size, otherwise from the frequent queue.
+func (c *TwoQueueCache[K, V]) ensureSpace(ghostHit bool) (key K, value V, removed bool) {
+	if c.recent.len()+c.frequent.len() < c.size {
+		return
+	}
+
+	if c.evictRecent(ghostHit) {
+		key, value, removed = c.recent.removeOldest()
+		if c.ghost.len() >= c.ghostSize {
+			c.ghost.removeOldest()
+		}
+		c.ghost.pushFront(key, struct{}{})
+	} else {
+		key, value, removed = c.frequent.removeOldest()
+	}
+	if removed {
+		c.stats.evicted(EvictCapacity, 1)
+	}
+
+	return
+}
+
+// evictRecent reports whether the next value should be evicted from the recent queue.
+func (c *TwoQueueCache[K, V]) evictRecent(ghostHit bool) bool {
+	recent := c.recent.len()
+	return recent > 0 && (recent > c.recentSize || (recent == c.recentSize && !ghostHit))
+}
+
+// victim returns the key of the value which would be evicted next.
+func (c *TwoQueueCache[K, V]) victim(ghostHit bool) (K, bool) {
+	if c.evictRecent(ghostHit) {
+		return c.recent.oldest()
+	}
+	return c.frequent.oldest()
+}
+
+// Get returns the element for the key if the element is present in the cache.
+// The elements from the recent queue are promoted into the frequent queue.
+func (c *TwoQueueCache[K, V]) Get(key K) (value V, available bool) {
+	c.admission.record(key)
+	if nd, ok := c.frequent.get(key); ok {
+		c.frequent.moveFront(nd)
+		c.stats.hit()
+		return nd.value, true
+	}
+	if value, ok := c.recent.remove(key); ok {
+		c.frequent.pushFront(key, value)
+		c.stats.hit()
+		return value, true
+	}
+	if value, available = c.window.get(key); available {
+		c.stats.hit()
+		return
+	}
+	c.stats.miss()
+	return
+}
+
+// Peek returns the element for the key if the element is present in the cache, without promoting it.
+func (c *TwoQueueCache[K, V]) Peek(key K) (value V, available bool) {
+	if nd, ok := c.frequent.get(key); ok {
+		return nd.value, true
+	}
+	if nd, ok := c.recent.get(key); ok {
+		return nd.value, true
+	}
+	return c.window.peek(key)
+}
+
+// Contains reports whether the key is present in the cache, without promoting it.
+func (c *TwoQueueCache[K, V]) Contains(key K) bool {
+	return c.frequent.contains(key) || c.recent.contains(key) || c.window.contains(key)
+}
+
+// Remove removes an element from the cache denoted by the key. The value removed is returned.
+func (c *TwoQueueCache[K, V]) Remove(key K) (value V, removed bool) {
+	if value, removed = c.frequent.remove(key); !removed {
+		value, removed = c.recent.remove(key)
+	}
+	if !removed {
+		value, removed = c.window.remove(key)
+	}
+	c.ghost.remove(key)
+	if removed {
+		c.stats.evicted(EvictDeleted, 1)
+	}
+	return
+}
+
+// Count returns the number of the current values from the cache.
+func (c *TwoQueueCache[K, V]) Count() int {
+	return c.recent.len() + c.frequent.len() + c.window.len()
+}
+
+// Flush clears all values from the cache.
+func (c *TwoQueueCache[K, V]) Flush() {
+	c.stats.evicted(EvictFlushed, c.Count())
+	c.recent.reset()
+	c.frequent.reset()
+	c.ghost.reset()
+	c.window.reset()
+	c.admission.reset()
+}
+
+// Stats returns a snapshot of the cache statistics.
+// The statistics are collected only if the cache was created with the WithStats option.
+func (c *TwoQueueCache[K, V]) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (c *TwoQueueCache[K, V]) ResetStats() {
+	c.stats.reset()
+}
+
+// AsStore returns an adapter exposing the 2Q cache through the Store interface.
+func (c *TwoQueueCache[K, V]) AsStore() Store[K, V] {
+	return boundedStore[K, V]{c}
+}
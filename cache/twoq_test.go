@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoQueueCache_Promotion(t *testing.T) {
+	assert := assert.New(t)
+
+	c, _ := NewTwoQueue[int, int](4)
+	for i := 0; i < 4; i++ {
+		c.Add(i, i)
+	}
+	// The accessed value is promoted into the frequent queue.
+	c.Get(0)
+	assert.True(c.frequent.contains(0))
+	assert.Equal(3, c.recent.len())
+
+	// The values from the recent queue are evicted first and remembered in the ghost queue.
+	key, _, removed := c.Add(4, 4)
+	assert.True(removed)
+	assert.Equal(1, key)
+	assert.True(c.ghost.contains(1))
+
+	// The value added again shortly after its eviction goes directly into the frequent queue.
+	c.Add(1, 1)
+	assert.True(c.frequent.contains(1))
+	assert.False(c.ghost.contains(1))
+	assert.Equal(4, c.Count())
+}
@@ -0,0 +1,95 @@
+// Package clock provides an abstraction over the time related functions of the standard library.
+// The real clock delegates to the time package, while the fake clock is advanced manually,
+// which makes the time dependent code testable in a deterministic way, without sleeping.
+package clock
+
+import "time"
+
+// Clock is the interface of the time source used by the time dependent functions.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// Since returns the time elapsed since t.
+	Since(t time.Time) time.Duration
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// Sleep pauses the current goroutine for at least the duration d.
+	Sleep(d time.Duration)
+	// NewTimer creates a new Timer that will send the current time on its channel after at least duration d.
+	NewTimer(d time.Duration) *Timer
+	// AfterFunc waits for the duration to elapse and then calls f.
+	// It returns a Timer that can be used to cancel the call using its Stop method.
+	AfterFunc(d time.Duration, f func()) *Timer
+	// NewTicker returns a new Ticker which sends the current time on its channel after each tick,
+	// with the period specified by d. It panics if d is not positive.
+	NewTicker(d time.Duration) *Ticker
+}
+
+// Timer is the counterpart of time.Timer, created by a Clock. For the timers created
+// with AfterFunc the channel is nil, the same as for the timers of the time package.
+type Timer struct {
+	C <-chan time.Time
+
+	stop  func() bool
+	reset func(d time.Duration) bool
+}
+
+// Stop prevents the Timer from firing. It returns true if the call stops the timer,
+// false if the timer has already expired or been stopped.
+func (t *Timer) Stop() bool {
+	return t.stop()
+}
+
+// Reset changes the timer to expire after duration d. It returns true
+// if the timer had been active, false if the timer had expired or been stopped.
+func (t *Timer) Reset(d time.Duration) bool {
+	return t.reset(d)
+}
+
+// newTimer wraps a timer of the time package.
+func newTimer(t *time.Timer) *Timer {
+	return &Timer{C: t.C, stop: t.Stop, reset: t.Reset}
+}
+
+// Ticker is the counterpart of time.Ticker, created by a Clock.
+// The ticks are dropped if the receiver doesn't keep up with them, the same as for the tickers of the time package.
+type Ticker struct {
+	C <-chan time.Time
+
+	stop  func()
+	reset func(d time.Duration)
+}
+
+// Stop turns off the ticker. After Stop, no more ticks will be sent.
+func (t *Ticker) Stop() {
+	t.stop()
+}
+
+// Reset stops the ticker and resets its period to the specified duration.
+// The next tick will arrive after the new period elapses. It panics if d is not positive.
+func (t *Ticker) Reset(d time.Duration) {
+	t.reset(d)
+}
+
+// realClock implements the Clock interface using the time package.
+type realClock struct{}
+
+// New returns the real clock, which delegates to the time package.
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+func (realClock) Sleep(d time.Duration)                  { time.Sleep(d) }
+func (realClock) NewTimer(d time.Duration) *Timer        { return newTimer(time.NewTimer(d)) }
+
+func (realClock) AfterFunc(d time.Duration, f func()) *Timer {
+	return newTimer(time.AfterFunc(d, f))
+}
+
+func (realClock) NewTicker(d time.Duration) *Ticker {
+	t := time.NewTicker(d)
+	return &Ticker{C: t.C, stop: t.Stop, reset: t.Reset}
+}
@@ -0,0 +1,139 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClock_Real(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := New()
+	start := clk.Now()
+	clk.Sleep(time.Millisecond)
+	<-clk.After(time.Millisecond)
+	assert.GreaterOrEqual(clk.Since(start), 2*time.Millisecond)
+
+	ticker := clk.NewTicker(time.Millisecond)
+	<-ticker.C
+	ticker.Stop()
+}
+
+func TestClock_Fake(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+	assert.Equal(start, clk.Now())
+
+	select {
+	case <-clk.After(0):
+	default:
+		assert.Fail("a non-positive duration should fire immediately")
+	}
+
+	ch1 := clk.After(time.Second)
+	ch2 := clk.After(2 * time.Second)
+	assert.Equal(2, clk.Waiters())
+
+	clk.Advance(time.Second)
+	assert.Equal(start.Add(time.Second), <-ch1)
+	assert.Equal(1, clk.Waiters())
+	select {
+	case <-ch2:
+		assert.Fail("the waiter should not fire before its deadline")
+	default:
+	}
+
+	clk.Set(start.Add(time.Minute))
+	assert.Equal(start.Add(time.Minute), <-ch2)
+	assert.Equal(time.Minute, clk.Since(start))
+	assert.Equal(0, clk.Waiters())
+
+	done := make(chan struct{})
+	go func() {
+		clk.Sleep(time.Hour)
+		close(done)
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	<-done
+}
+
+func TestClock_FakeTimers(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+
+	var calls int
+	timer := clk.AfterFunc(time.Second, func() { calls++ })
+	assert.Nil(timer.C)
+	clk.Advance(500 * time.Millisecond)
+	assert.Equal(0, calls)
+
+	// Reset reschedules the function relative to the current time.
+	assert.True(timer.Reset(time.Second))
+	clk.Advance(500 * time.Millisecond)
+	assert.Equal(0, calls)
+	clk.Advance(500 * time.Millisecond)
+	assert.Equal(1, calls)
+	assert.False(timer.Stop())
+
+	timer = clk.AfterFunc(time.Second, func() { calls++ })
+	assert.True(timer.Stop())
+	clk.Advance(time.Hour)
+	assert.Equal(1, calls)
+	assert.Equal(0, clk.Waiters())
+
+	timer = clk.NewTimer(time.Second)
+	clk.Advance(time.Second)
+	assert.Equal(start.Add(time.Hour+2500*time.Millisecond), <-timer.C)
+	assert.False(timer.Reset(time.Second))
+	clk.Advance(time.Second)
+	assert.Equal(start.Add(time.Hour+3500*time.Millisecond), <-timer.C)
+}
+
+func TestClock_FakeTicker(t *testing.T) {
+	assert := assert.New(t)
+
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := NewFake(start)
+
+	assert.Panics(func() { clk.NewTicker(0) })
+
+	ticker := clk.NewTicker(time.Second)
+	clk.Advance(500 * time.Millisecond)
+	select {
+	case <-ticker.C:
+		t.Fatal("the ticker ticked before the end of its period")
+	default:
+	}
+
+	clk.Advance(500 * time.Millisecond)
+	assert.Equal(start.Add(time.Second), <-ticker.C)
+	assert.Equal(1, clk.Waiters())
+
+	// Advancing the clock by multiple periods results in a single tick.
+	clk.Advance(3 * time.Second)
+	assert.Equal(start.Add(4*time.Second), <-ticker.C)
+	select {
+	case <-ticker.C:
+		t.Fatal("the ticks should have been dropped")
+	default:
+	}
+
+	// Reset restarts the period relative to the current time.
+	ticker.Reset(2 * time.Second)
+	clk.Advance(time.Second)
+	assert.Len(ticker.C, 0)
+	clk.Advance(time.Second)
+	assert.Equal(start.Add(6*time.Second), <-ticker.C)
+
+	ticker.Stop()
+	assert.Equal(0, clk.Waiters())
+	clk.Advance(time.Hour)
+	assert.Len(ticker.C, 0)
+}
@@ -0,0 +1,236 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// waiter is a pending operation of the fake clock, which is fired once the clock reaches its deadline.
+// It either sends the current time on its channel or invokes its function.
+// The waiters of the tickers have a period, after which they are fired again.
+type waiter struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	fn       func()
+}
+
+// fire completes the operation of the waiter.
+func (w *waiter) fire(now time.Time) {
+	if w.fn != nil {
+		w.fn()
+		return
+	}
+	select {
+	case w.ch <- now:
+	default:
+	}
+}
+
+// Fake is a clock which is advanced only manually, by calling the Advance or Set methods.
+// The operations waiting for a duration to elapse (like After, Sleep, the timers and the
+// functions scheduled with AfterFunc) are completed once the clock is advanced past their deadline.
+// The functions scheduled with AfterFunc are invoked by Advance and Set, before they return.
+// It's safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*waiter
+}
+
+var _ Clock = (*Fake)(nil)
+
+// NewFake returns a fake clock set to the provided time.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.cond = sync.NewCond(&f.mu)
+
+	return f
+}
+
+// Now returns the current time of the fake clock.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// Since returns the time elapsed since t, according to the fake clock.
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After returns a channel on which the current time is sent once the clock is advanced by at least d.
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C
+}
+
+// Sleep blocks until the clock is advanced by at least d.
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+// NewTimer returns a timer which sends the current time on its channel once the clock is advanced by at least d.
+func (f *Fake) NewTimer(d time.Duration) *Timer {
+	w := &waiter{ch: make(chan time.Time, 1)}
+	t := f.newTimer(w)
+	t.C = w.ch
+	f.schedule(w, d)
+
+	return t
+}
+
+// AfterFunc returns a timer which invokes f once the clock is advanced by at least d.
+func (f *Fake) AfterFunc(d time.Duration, fn func()) *Timer {
+	w := &waiter{fn: fn}
+	t := f.newTimer(w)
+	f.schedule(w, d)
+
+	return t
+}
+
+// NewTicker returns a ticker which sends the current time on its channel each time the clock
+// is advanced past the end of a period. The ticks are dropped if the receiver doesn't keep up with them,
+// which also means that advancing the clock by multiple periods at once results in a single tick.
+func (f *Fake) NewTicker(d time.Duration) *Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+
+	w := &waiter{ch: make(chan time.Time, 1), period: d}
+	f.schedule(w, d)
+
+	return &Ticker{
+		C: w.ch,
+		stop: func() {
+			f.mu.Lock()
+			f.remove(w)
+			f.mu.Unlock()
+		},
+		reset: func(d time.Duration) {
+			if d <= 0 {
+				panic("clock: non-positive interval for Ticker.Reset")
+			}
+			f.mu.Lock()
+			f.remove(w)
+			w.period = d
+			f.mu.Unlock()
+			f.schedule(w, d)
+		},
+	}
+}
+
+// newTimer returns a timer controlling the waiter.
+func (f *Fake) newTimer(w *waiter) *Timer {
+	return &Timer{
+		stop: func() bool {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+
+			return f.remove(w)
+		},
+		reset: func(d time.Duration) bool {
+			f.mu.Lock()
+			active := f.remove(w)
+			f.mu.Unlock()
+			f.schedule(w, d)
+
+			return active
+		},
+	}
+}
+
+// schedule adds the waiter to the pending operations, or fires it immediately if d is not positive.
+func (f *Fake) schedule(w *waiter, d time.Duration) {
+	f.mu.Lock()
+	now := f.now
+	w.deadline = now.Add(d)
+	if d > 0 {
+		f.waiters = append(f.waiters, w)
+		f.cond.Broadcast()
+	}
+	f.mu.Unlock()
+
+	if d <= 0 {
+		w.fire(now)
+	}
+}
+
+// remove removes the waiter from the pending operations and reports whether it was pending.
+// It must be called while holding the lock.
+func (f *Fake) remove(w *waiter) bool {
+	for i, pending := range f.waiters {
+		if pending == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by d, completing the operations whose deadline has been reached.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.set(f.now.Add(d))
+}
+
+// Set sets the clock to the provided time, completing the operations whose deadline has been reached.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	f.set(t)
+}
+
+// set changes the current time and fires the due waiters in the order of their deadline.
+// It must be called while holding the lock, which is released before firing the waiters.
+func (f *Fake) set(t time.Time) {
+	f.now = t
+
+	var due, pending []*waiter
+	for _, w := range f.waiters {
+		if !w.deadline.After(t) {
+			due = append(due, w)
+		} else {
+			pending = append(pending, w)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].deadline.Before(due[j].deadline)
+	})
+	// The tickers are rescheduled for the end of their next period.
+	for _, w := range due {
+		if w.period > 0 {
+			for !w.deadline.After(t) {
+				w.deadline = w.deadline.Add(w.period)
+			}
+			pending = append(pending, w)
+		}
+	}
+	f.waiters = pending
+	f.mu.Unlock()
+
+	for _, w := range due {
+		w.fire(t)
+	}
+}
+
+// Waiters returns the number of the operations waiting for the clock to be advanced.
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.waiters)
+}
+
+// BlockUntil blocks until at least n operations are waiting for the clock to be advanced.
+// It's useful for synchronizing the test with the goroutine under test before advancing the clock.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
@@ -1,11 +1,15 @@
 package gogu
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/esimov/gogu/cache"
+	"github.com/esimov/gogu/clock"
 	"golang.org/x/exp/constraints"
 )
 
@@ -16,12 +20,94 @@ func Flip[T any](fn func(args ...T) []T) func(args ...T) []T {
 	}
 }
 
+// timeConfig holds the optional settings of the time based functions.
+type timeConfig struct {
+	clock    clock.Clock
+	leading  bool
+	trailing bool
+}
+
+// TimeOption customizes the time based functions, like Delay, DelayCtx, NewDebounce, NewThrottle and RetryWithDelay.
+type TimeOption func(*timeConfig)
+
+// WithClock sets the clock used for measuring and waiting for the time to elapse.
+// The real clock is used by default, while a fake clock (see clock.NewFake)
+// makes the time based functions testable without waiting for the time to elapse.
+func WithClock(clk clock.Clock) TimeOption {
+	return func(cfg *timeConfig) {
+		cfg.clock = clk
+	}
+}
+
+// WithLeading sets whether the function throttled with Throttled is invoked on the leading edge of the wait period.
+// It's enabled by default.
+func WithLeading(leading bool) TimeOption {
+	return func(cfg *timeConfig) {
+		cfg.leading = leading
+	}
+}
+
+// WithTrailing sets whether the function throttled with Throttled is invoked on the trailing edge of the wait period,
+// in case it was called again during the wait period. It's enabled by default.
+func WithTrailing(trailing bool) TimeOption {
+	return func(cfg *timeConfig) {
+		cfg.trailing = trailing
+	}
+}
+
+// newTimeConfig applies the options on top of the default settings.
+func newTimeConfig(opts []TimeOption) timeConfig {
+	cfg := timeConfig{leading: true, trailing: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.clock == nil {
+		cfg.clock = clock.New()
+	}
+
+	return cfg
+}
+
 // Delay invokes the callback function with a predefined delay.
-func Delay(delay time.Duration, fn func()) *time.Timer {
-	t := time.AfterFunc(delay, fn)
+// The delay is measured by the clock set with the WithClock option, which makes it possible to invoke
+// the callback function without waiting for it by using a fake clock (see clock.NewFake). Since the returned
+// timer is not driven by such a clock, in this case it can only be used for canceling the invocation with Stop.
+func Delay(delay time.Duration, fn func(), opts ...TimeOption) *time.Timer {
+	clk := newTimeConfig(opts).clock
+	if clk == clock.New() {
+		return time.AfterFunc(delay, fn)
+	}
+
+	// The timer never fires, it only records whether the invocation was canceled.
+	t := time.AfterFunc(math.MaxInt64, func() {})
+	clk.AfterFunc(delay, func() {
+		if t.Stop() {
+			fn()
+		}
+	})
+
 	return t
 }
 
+// DelayCtx waits for the delay to elapse and then invokes the callback function.
+// If the context is canceled before, the callback function is not invoked and the context error is returned.
+func DelayCtx(ctx context.Context, delay time.Duration, fn func(), opts ...TimeOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	t := newTimeConfig(opts).clock.NewTimer(delay)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		fn()
+		return nil
+	}
+}
+
 // After creates a function wrapper that does nothing at first.
 // From the nth call onwards, it starts actually invoking the callback function.
 // Useful for grouping responses, where you need to be sure that all
@@ -36,32 +122,79 @@ func After[V constraints.Signed](n *V, fn func()) {
 // Before creates a function wrapper that memoizes its return value.
 // From the nth call onwards, the memoized result of the last invocation is returned immediately
 // instead of invoking function again. So the wrapper will invoke function at most n-1 times.
-func Before[S ~string, T any, V constraints.Signed](n *V, c *cache.Cache[S, T], fn func() T) T {
-	var memo *cache.Item[T]
+// The result is stored in the cache under the "func" key for string based keys, otherwise under a key
+// derived from the function (see funcKey). Use BeforeWithStore for choosing the key explicitly.
+func Before[S comparable, T any, V constraints.Signed](n *V, c *cache.Cache[S, T], fn func() T) T {
+	return BeforeWithStore(n, c.AsStore(), funcKey[S](fn), fn)
+}
+
+// BeforeWithStore is like Before, but the result of the function invocation is memorized
+// under the provided key into the provided store, which can be backed by any cache type.
+func BeforeWithStore[S comparable, T any, V constraints.Signed](n *V, s cache.Store[S, T], key S, fn func() T) T {
 	*n-- // decrease the n as pointer receiver
 	if *n > 0 {
 		return fn()
 	}
 	if *n == 0 {
-		c.Set("func", fn(), cache.DefaultExpiration)
+		s.Set(key, fn())
 	}
-	memo, _ = c.Get("func")
+	memo, _ := s.Get(key)
 
-	return memo.Val()
+	return memo
 }
 
 // Once is like Before, but it's invoked only once.
 // Repeated calls to the modified function will have no effect
 // and the function invocation is returned from the cache.
-func Once[S ~string, T comparable, V constraints.Signed](c *cache.Cache[S, T], fn func() T) T {
-	memo, _ := c.Get("func")
-	if memo == nil {
-		c.Set("func", fn(), cache.DefaultExpiration)
-		return fn()
+// The result is stored under the same key as in the case of Before.
+func Once[S comparable, T comparable](c *cache.Cache[S, T], fn func() T) T {
+	return OnceWithStore(c.AsStore(), funcKey[S](fn), fn)
+}
+
+// OnceWithStore is like Once, but the result of the function invocation is memorized
+// under the provided key into the provided store, which can be backed by any cache type.
+func OnceWithStore[S comparable, T any](s cache.Store[S, T], key S, fn func() T) T {
+	if memo, ok := s.Get(key); ok {
+		return memo
+	}
+	memo := fn()
+	s.Set(key, memo)
+
+	return memo
+}
+
+// funcKey returns the key under which Before and Once store the result of the function.
+// It is "func" for string based keys, as it has always been. For the 64-bit integer based keys it's
+// the address of the function code, shared by all the closures created from the same function literal,
+// while for the interface keys it's a value of an unexported type, which can't collide with the caller keys.
+// The keys of any other type can't be derived from the function, so the zero value is used.
+func funcKey[K comparable](fn any) K {
+	var key K
+
+	v := reflect.ValueOf(&key).Elem()
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString("func")
+	case reflect.Int, reflect.Int64:
+		if v.Type().Size() == 8 {
+			v.SetInt(int64(reflect.ValueOf(fn).Pointer()))
+		}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		if v.Type().Size() == 8 {
+			v.SetUint(uint64(reflect.ValueOf(fn).Pointer()))
+		}
+	case reflect.Interface:
+		mk := reflect.ValueOf(memoKey{reflect.ValueOf(fn).Pointer()})
+		if mk.Type().Implements(v.Type()) {
+			v.Set(mk)
+		}
 	}
-	memo, _ = c.Get("func")
+	return key
+}
 
-	return memo.Val()
+// memoKey is the key under which Before and Once store the result of the function for the interface keys.
+type memoKey struct {
+	fn uintptr
 }
 
 // RType is a generic struct type used as method receiver on retry operations.
@@ -70,7 +203,8 @@ type RType[T any] struct {
 }
 
 // Retry tries to invoke the callback function `n` times.
-// It runs until the number of attempts is reached or the returned value of the callback function is nil.
+// It runs until the number of attempts is reached, the returned value of the callback function is nil
+// or a permanent error (see Permanent), in which case the error wrapped by Permanent is returned.
 func (v RType[T]) Retry(n int, fn func(T) error) (int, error) {
 	var (
 		err     error
@@ -85,6 +219,9 @@ func (v RType[T]) Retry(n int, fn func(T) error) (int, error) {
 		if err = fn(v.Input); err == nil {
 			return attempt, nil
 		}
+		if cause, ok := permanentCause(err); ok {
+			return attempt, cause
+		}
 		attempt++
 	}
 
@@ -92,29 +229,36 @@ func (v RType[T]) Retry(n int, fn func(T) error) (int, error) {
 }
 
 // RetryWithDelay tries to invoke the callback function `n` times, but with a delay between each call.
-// It runs until the number of attempts is reached or the error return value of the callback function is nil.
-func (v RType[T]) RetryWithDelay(n int, delay time.Duration, fn func(time.Duration, T) error) (time.Duration, int, error) {
+// It runs until the number of attempts is reached, the error return value of the callback function is nil
+// or a permanent error (see Permanent), in which case the error wrapped by Permanent is returned.
+func (v RType[T]) RetryWithDelay(n int, delay time.Duration, fn func(time.Duration, T) error, opts ...TimeOption) (time.Duration, int, error) {
 	var (
 		err     error
 		attempt int
 	)
 
-	start := time.Now()
+	clk := newTimeConfig(opts).clock
+	start := clk.Now()
 	for attempt < n {
-		err = fn(time.Since(start), v.Input)
+		err = fn(clk.Since(start), v.Input)
 		if err == nil {
-			return time.Since(start), attempt, nil
+			return clk.Since(start), attempt, nil
+		}
+		if cause, ok := permanentCause(err); ok {
+			return clk.Since(start), attempt, cause
 		}
-		<-time.After(delay)
+		<-clk.After(delay)
 		attempt++
 	}
 
-	return time.Since(start), attempt, err
+	return clk.Since(start), attempt, err
 }
 
 type debouncer struct {
+	ctx      context.Context
 	mu       sync.Mutex
-	timer    *time.Timer
+	clock    clock.Clock
+	timer    *clock.Timer
 	duration time.Duration
 }
 
@@ -122,23 +266,41 @@ type debouncer struct {
 // postpone the execution with a time delay passed in as a function argument.
 // It returns a callback function which will be invoked after the predefined delay and
 // also a cancel method which should be invoked to cancel a scheduled debounce.
-func NewDebounce(wait time.Duration) (func(f func()), func()) {
-	d := &debouncer{duration: wait}
+func NewDebounce(wait time.Duration, opts ...TimeOption) (func(f func()), func()) {
+	d := &debouncer{ctx: context.Background(), clock: newTimeConfig(opts).clock, duration: wait}
 	return func(f func()) {
 		d.add(f)
 	}, d.cancel
 }
 
+// NewDebounceCtx is like NewDebounce, but the debounced function is bound to the context.
+// Once the context is canceled the scheduled function is not invoked anymore
+// and the callback function returns the context error instead of scheduling a new execution.
+func NewDebounceCtx(ctx context.Context, wait time.Duration, opts ...TimeOption) (func(f func()) error, func()) {
+	d := &debouncer{ctx: ctx, clock: newTimeConfig(opts).clock, duration: wait}
+	return d.add, d.cancel
+}
+
 // add method schedules the execution of the passed in function after a predefined delay.
-func (d *debouncer) add(f func()) {
+// It returns the context error without scheduling the function if the context of the debouncer is canceled.
+func (d *debouncer) add(f func()) error {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
 	if d.timer != nil {
 		d.timer.Stop()
 	}
+	if err := d.ctx.Err(); err != nil {
+		d.timer = nil
+		return err
+	}
 
-	d.timer = time.AfterFunc(d.duration, f)
+	d.timer = d.clock.AfterFunc(d.duration, func() {
+		if d.ctx.Err() == nil {
+			f()
+		}
+	})
+	return nil
 }
 
 // cancel the execution of a scheduled debounce function.
@@ -155,6 +317,7 @@ func (d *debouncer) cancel() {
 // The throttle implementation is based on this package: https://github.com/boz/go-throttle.
 type throttler struct {
 	last     time.Time
+	clock    clock.Clock
 	cond     *sync.Cond
 	duration time.Duration
 	waiting  bool
@@ -169,8 +332,10 @@ type throttler struct {
 // In this case the code will be executed one more time at the beginning of the next period.
 //
 // This function is useful for rate-limiting events that occur faster than you can keep up with.
-func NewThrottle(wait time.Duration, trailing bool) *throttler {
+// Throttled provides a callback based alternative, which doesn't require running a loop calling Next.
+func NewThrottle(wait time.Duration, trailing bool, opts ...TimeOption) *throttler {
 	t := &throttler{
+		clock: newTimeConfig(opts).clock,
 		cond: &sync.Cond{
 			L: new(sync.Mutex),
 		},
@@ -186,13 +351,13 @@ func (t *throttler) Call() {
 	defer t.cond.L.Unlock()
 
 	if !t.waiting && !t.stop {
-		delta := time.Since(t.last)
+		delta := t.clock.Since(t.last)
 		if delta > t.duration {
 			t.waiting = true
 			t.cond.Broadcast()
 		} else if t.trailing {
 			t.waiting = true
-			time.AfterFunc(t.duration-delta, t.cond.Broadcast)
+			t.clock.AfterFunc(t.duration-delta, t.cond.Broadcast)
 		}
 	}
 }
@@ -208,7 +373,7 @@ func (t *throttler) Next() bool {
 
 	if !t.stop {
 		t.waiting = false
-		t.last = time.Now()
+		t.last = t.clock.Now()
 	}
 
 	return !t.stop
@@ -222,3 +387,126 @@ func (t *throttler) Cancel() {
 	t.stop = true
 	t.cond.Broadcast()
 }
+
+// Throttle controls a throttled function, which is invoked at most once per wait period.
+// It's returned by Throttled together with the throttled function and it's safe for concurrent use.
+type Throttle struct {
+	mu       sync.Mutex
+	fn       func()
+	wait     time.Duration
+	leading  bool
+	trailing bool
+	clock    clock.Clock
+	timer    *clock.Timer
+	pending  bool
+	gen      uint64
+}
+
+// Throttled wraps the passed in function into a throttled function, which invokes it at most once per wait period.
+// Following the lodash semantics, the function is invoked on the leading edge of the wait period, and also
+// on its trailing edge if it was called again during the wait period. The invocation on the trailing edge
+// starts a new wait period. Any of the edges can be disabled with the WithLeading and WithTrailing options,
+// while the clock can be replaced with the WithClock option. The returned Throttle can be used for flushing
+// or canceling the pending invocation.
+//
+// The wait periods are tracked with timers, so no goroutine is needed for driving the throttled function.
+func Throttled(fn func(), wait time.Duration, opts ...TimeOption) (func(), *Throttle) {
+	cfg := newTimeConfig(opts)
+
+	t := &Throttle{
+		fn:       fn,
+		wait:     wait,
+		leading:  cfg.leading,
+		trailing: cfg.trailing,
+		clock:    cfg.clock,
+	}
+
+	return t.Call, t
+}
+
+// Call invokes the throttled function, either immediately, on the trailing edge of the wait period or not at all,
+// depending on the time elapsed since the last invocation. It's the throttled function returned by Throttled.
+func (t *Throttle) Call() {
+	if t.wait <= 0 {
+		t.fn()
+		return
+	}
+
+	t.mu.Lock()
+	if t.timer != nil {
+		t.pending = true
+		t.mu.Unlock()
+		return
+	}
+	t.start()
+	invoke := t.leading
+	t.pending = !invoke
+	t.mu.Unlock()
+
+	if invoke {
+		t.fn()
+	}
+}
+
+// Flush immediately invokes the throttled function if an invocation is pending on the trailing edge
+// of the current wait period, and ends the wait period.
+func (t *Throttle) Flush() {
+	t.mu.Lock()
+	invoke := t.trailing && t.pending
+	t.reset()
+	t.mu.Unlock()
+
+	if invoke {
+		t.fn()
+	}
+}
+
+// Cancel cancels the pending invocation of the throttled function and ends the current wait period.
+func (t *Throttle) Cancel() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.reset()
+}
+
+// start starts a new wait period. It must be called while holding the lock.
+func (t *Throttle) start() {
+	t.gen++
+	gen := t.gen
+	t.timer = t.clock.AfterFunc(t.wait, func() {
+		t.expire(gen)
+	})
+}
+
+// expire is invoked at the end of the wait period started with the provided generation.
+// It invokes the pending trailing call, which starts a new wait period.
+func (t *Throttle) expire(gen uint64) {
+	t.mu.Lock()
+	if gen != t.gen {
+		// The wait period has been ended by Flush or Cancel.
+		t.mu.Unlock()
+		return
+	}
+	invoke := t.trailing && t.pending
+	t.pending = false
+	if invoke {
+		t.start()
+	} else {
+		t.timer = nil
+	}
+	t.mu.Unlock()
+
+	if invoke {
+		t.fn()
+	}
+}
+
+// reset ends the current wait period and drops the pending invocation. It must be called while holding the lock.
+func (t *Throttle) reset() {
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.pending = false
+	t.gen++
+}
@@ -1,6 +1,8 @@
 package gogu
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -9,6 +11,7 @@ import (
 	"time"
 
 	"github.com/esimov/gogu/cache"
+	"github.com/esimov/gogu/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -57,6 +60,29 @@ func TestFunc_Delay(t *testing.T) {
 	assert.LessOrEqual(int(after), 200)
 }
 
+func TestFunc_DelayCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	var called bool
+	err := DelayCtx(context.Background(), time.Millisecond, func() { called = true })
+	assert.NoError(err)
+	assert.True(called)
+
+	called = false
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = DelayCtx(ctx, time.Hour, func() { called = true })
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Less(time.Since(start), time.Minute)
+	assert.False(called)
+
+	err = DelayCtx(ctx, 0, func() { called = true })
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.False(called)
+}
+
 func Example_delay() {
 	ch := make(chan struct{})
 
@@ -175,8 +201,9 @@ func Example_before() {
 		}
 		if n <= 0 {
 			// Here the callback function is served from the cache.
+			// Only the item value is printed, since the item also holds the cache bookkeeping fields.
 			val, _ := c.Get("func")
-			fmt.Println(val)
+			fmt.Println(val.Val())
 			fmt.Println(res)
 		}
 	})
@@ -186,7 +213,7 @@ func Example_before() {
 	// 2
 	// <nil>
 	// 1
-	// &{0 0}
+	// 0
 	// 0
 }
 
@@ -201,7 +228,7 @@ func TestFunc_Once(t *testing.T) {
 				return val
 			}
 		}
-		res := Once[string, int, int](c, fn(val))
+		res := Once[string, int](c, fn(val))
 		// We can test the implementation correctness by invoking the `Once` function multiple times.
 		// When it's invoked for the first time the result should be served from the callback function.
 		// From the second invocation onward the results are served from the cache.
@@ -221,7 +248,7 @@ func Example_once() {
 				return val
 			}
 		}
-		res := Once[string, int, int](c, fn(val))
+		res := Once[string, int](c, fn(val))
 		// We can test the implementation correctness by invoking the `Once` function multiple times.
 		// When it's invoked for the first time the result should be served from the callback function.
 		// From the second invocation onward the results are served from the cache.
@@ -489,6 +516,33 @@ func TestFunc_Debounce(t *testing.T) {
 	assert.Equal(1, int(c3))
 }
 
+func TestFunc_DebounceCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	var counter uint64
+	f := func() {
+		atomic.AddUint64(&counter, 1)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	debounce, _ := NewDebounceCtx(ctx, 10*time.Millisecond)
+	for i := 0; i < 10; i++ {
+		assert.NoError(debounce(f))
+	}
+	<-time.After(30 * time.Millisecond)
+	assert.Equal(uint64(1), atomic.LoadUint64(&counter))
+
+	// The scheduled function is not invoked once the context is canceled.
+	assert.NoError(debounce(f))
+	cancel()
+	<-time.After(30 * time.Millisecond)
+	assert.Equal(uint64(1), atomic.LoadUint64(&counter))
+
+	assert.ErrorIs(debounce(f), context.Canceled)
+	<-time.After(30 * time.Millisecond)
+	assert.Equal(uint64(1), atomic.LoadUint64(&counter))
+}
+
 func Example_debounce() {
 	var (
 		counter1 uint64
@@ -592,3 +646,216 @@ func TestFunc_ThrottlePings(t *testing.T) {
 
 	assert.Equal(0, count)
 }
+
+func TestFunc_Throttled(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+
+	var counter int
+	fn := func() { counter++ }
+
+	// Leading and trailing edges.
+	throttled, throttle := Throttled(fn, time.Second, WithClock(clk))
+	for i := 0; i < 10; i++ {
+		throttled()
+	}
+	assert.Equal(1, counter)
+	clk.Advance(time.Second)
+	assert.Equal(2, counter)
+	// The trailing invocation started a new wait period.
+	throttled()
+	assert.Equal(2, counter)
+	clk.Advance(time.Second)
+	assert.Equal(3, counter)
+	clk.Advance(time.Second)
+	assert.Equal(3, counter)
+	throttled()
+	assert.Equal(4, counter)
+	clk.Advance(time.Second)
+	assert.Equal(0, clk.Waiters())
+
+	// Leading edge only.
+	counter = 0
+	throttled, throttle = Throttled(fn, time.Second, WithClock(clk), WithTrailing(false))
+	throttled()
+	throttled()
+	assert.Equal(1, counter)
+	clk.Advance(time.Second)
+	assert.Equal(1, counter)
+	throttled()
+	assert.Equal(2, counter)
+	clk.Advance(time.Second)
+
+	// Trailing edge only.
+	counter = 0
+	throttled, throttle = Throttled(fn, time.Second, WithClock(clk), WithLeading(false))
+	throttled()
+	throttled()
+	assert.Equal(0, counter)
+	clk.Advance(time.Second)
+	assert.Equal(1, counter)
+	clk.Advance(time.Second)
+	assert.Equal(1, counter)
+
+	// Flush invokes the pending call immediately and ends the wait period.
+	counter = 0
+	throttled, throttle = Throttled(fn, time.Second, WithClock(clk))
+	throttled()
+	throttled()
+	throttle.Flush()
+	assert.Equal(2, counter)
+	assert.Equal(0, clk.Waiters())
+	throttle.Flush()
+	assert.Equal(2, counter)
+	throttled()
+	assert.Equal(3, counter)
+
+	// Cancel drops the pending call.
+	throttled()
+	throttle.Cancel()
+	clk.Advance(time.Second)
+	assert.Equal(3, counter)
+	throttled()
+	assert.Equal(4, counter)
+	throttle.Cancel()
+
+	// The throttled function is safe for concurrent use.
+	var calls uint32
+	var wg sync.WaitGroup
+	throttled, throttle = Throttled(func() { atomic.AddUint32(&calls, 1) }, time.Second, WithClock(clk))
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			throttled()
+		}()
+	}
+	wg.Wait()
+	clk.Advance(time.Second)
+	assert.Equal(uint32(2), atomic.LoadUint32(&calls))
+}
+
+func TestFunc_Clock(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Now())
+
+	var delayed bool
+	Delay(time.Second, func() { delayed = true }, WithClock(clk))
+	clk.Advance(time.Second - 1)
+	assert.False(delayed)
+	clk.Advance(1)
+	assert.True(delayed)
+
+	// The returned timer cancels the invocation.
+	delayed = false
+	timer := Delay(time.Second, func() { delayed = true }, WithClock(clk))
+	assert.True(timer.Stop())
+	clk.Advance(time.Second)
+	assert.False(delayed)
+	assert.False(timer.Stop())
+
+	done := make(chan error)
+	go func() {
+		done <- DelayCtx(context.Background(), time.Minute, func() {}, WithClock(clk))
+	}()
+	clk.BlockUntil(1)
+	clk.Advance(time.Minute)
+	assert.NoError(<-done)
+
+	var counter int
+	debounce, _ := NewDebounce(time.Second, WithClock(clk))
+	for i := 0; i < 10; i++ {
+		debounce(func() { counter++ })
+		clk.Advance(time.Second / 2)
+	}
+	assert.Equal(0, counter)
+	clk.Advance(time.Second / 2)
+	assert.Equal(1, counter)
+
+	throttle := NewThrottle(time.Second, false, WithClock(clk))
+	throttle.Call()
+	assert.True(throttle.Next())
+	throttle.Call()
+	assert.False(throttle.waiting)
+	clk.Advance(2 * time.Second)
+	throttle.Call()
+	assert.True(throttle.waiting)
+	throttle.Cancel()
+
+	type result struct {
+		elapsed  time.Duration
+		attempts int
+	}
+	retried := make(chan result)
+	go func() {
+		rt := RType[int]{Input: 1}
+		d, attempts, err := rt.RetryWithDelay(3, time.Minute, func(time.Duration, int) error {
+			return errors.New("failed")
+		}, WithClock(clk))
+		assert.Error(err)
+		retried <- result{d, attempts}
+	}()
+	for i := 0; i < 3; i++ {
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+	}
+	assert.Equal(result{3 * time.Minute, 3}, <-retried)
+}
+
+func TestFunc_BeforeOnceComparableKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	c1 := cache.New[int, int](cache.DefaultExpiration, cache.NoExpiration)
+	c1.Set(0, 10, cache.DefaultExpiration)
+	n := 2
+	assert.Equal(1, Before(&n, c1, func() int { return 1 }))
+	assert.Equal(2, Before(&n, c1, func() int { return 2 }))
+	// The result is not stored under the zero value key, which could collide with the caller keys.
+	item, err := c1.Get(0)
+	assert.NoError(err)
+	assert.Equal(10, item.Val())
+	assert.Equal(2, c1.Count())
+
+	var calls int
+	fn := func() int {
+		calls++
+		return calls
+	}
+	c2 := cache.New[int, int](cache.DefaultExpiration, cache.NoExpiration)
+	assert.Equal(1, Once[int, int](c2, fn))
+	assert.Equal(1, Once[int, int](c2, fn))
+	assert.Equal(1, calls)
+
+	c3 := cache.New[any, int](cache.DefaultExpiration, cache.NoExpiration)
+	c3.Set(nil, 10, cache.DefaultExpiration)
+	assert.Equal(2, Once[any, int](c3, fn))
+	assert.Equal(2, Once[any, int](c3, fn))
+	item, _ = c3.Get(nil)
+	assert.Equal(10, item.Val())
+}
+
+func TestFunc_BeforeOnceWithStore(t *testing.T) {
+	assert := assert.New(t)
+
+	lru, err := cache.NewLRU[string, int](1)
+	assert.NoError(err)
+
+	n := 2
+	assert.Equal(1, BeforeWithStore(&n, lru.AsStore(), "key", func() int { return 1 }))
+	assert.Equal(2, BeforeWithStore(&n, lru.AsStore(), "key", func() int { return 2 }))
+	assert.Equal(2, BeforeWithStore(&n, lru.AsStore(), "key", func() int { return 3 }))
+	assert.True(lru.Contains("key"))
+
+	var calls int
+	s := cache.New[string, int](cache.DefaultExpiration, cache.NoExpiration).AsStore()
+	fn := func() int {
+		calls++
+		return calls
+	}
+	assert.Equal(1, OnceWithStore(s, "key", fn))
+	assert.Equal(1, OnceWithStore(s, "key", fn))
+	assert.Equal(2, OnceWithStore(s, "other", fn))
+	assert.Equal(2, calls)
+}
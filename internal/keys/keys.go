@@ -0,0 +1,181 @@
+// Package keys derives the string representation of the comparable keys,
+// used for keying the in-flight loads and for hashing the keys.
+package keys
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+var (
+	typeIDs sync.Map // map[reflect.Type]string
+	lastID  atomic.Uint64
+)
+
+// String returns a string uniquely identifying a comparable key: equal keys always produce
+// the same string, while different keys produce different strings. The dynamic type of the
+// values stored in interfaces is part of the string, so for example int(1) and int64(1)
+// stored in an interface key are different keys, the same as when compared with the == operator.
+func String[K comparable](key K) string {
+	var zero K
+	if _, ok := any(zero).(string); ok {
+		return any(key).(string)
+	}
+
+	// The static type of the key is known, so the basic types don't need to be tagged with their type.
+	if t := reflect.TypeOf(zero); t != nil {
+		v := reflect.ValueOf(key)
+		switch t.Kind() {
+		case reflect.String:
+			return v.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return strconv.FormatInt(v.Int(), 10)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return strconv.FormatUint(v.Uint(), 10)
+		}
+	}
+
+	var sb strings.Builder
+	// The key is encoded through a pointer, so that an interface key keeps its interface kind.
+	encode(&sb, reflect.ValueOf(&key).Elem())
+
+	return sb.String()
+}
+
+// encode writes the representation of the value into the builder.
+// The composite values are delimited, the strings are quoted and the interfaces are tagged
+// with the identifier of their dynamic type, which makes the representation unambiguous.
+func encode(sb *strings.Builder, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			sb.WriteString("nil")
+			return
+		}
+		elem := v.Elem()
+		sb.WriteString(typeID(elem.Type()))
+		sb.WriteByte('(')
+		encode(sb, elem)
+		sb.WriteByte(')')
+	case reflect.String:
+		sb.WriteString(strconv.Quote(v.String()))
+	case reflect.Bool:
+		sb.WriteString(strconv.FormatBool(v.Bool()))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sb.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		sb.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		writeFloat(sb, v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		sb.WriteByte('(')
+		writeFloat(sb, real(c))
+		sb.WriteByte(',')
+		writeFloat(sb, imag(c))
+		sb.WriteByte(')')
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		sb.WriteString("0x")
+		sb.WriteString(strconv.FormatUint(uint64(v.Pointer()), 16))
+	case reflect.Array:
+		sb.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			encode(sb, v.Index(i))
+		}
+		sb.WriteByte(']')
+	case reflect.Struct:
+		sb.WriteByte('{')
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			encode(sb, v.Field(i))
+		}
+		sb.WriteByte('}')
+	}
+}
+
+// writeFloat writes the shortest representation of the float which can be parsed back to the same value.
+// The negative zero is written as zero, since the two values are equal.
+func writeFloat(sb *strings.Builder, f float64) {
+	if f == 0 {
+		f = math.Abs(f)
+	}
+	sb.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// typeID returns the identifier of the type. Unlike the name of the type, which could be shared
+// by different types declared in different packages, the identifier is unique for each type.
+func typeID(t reflect.Type) string {
+	if id, ok := typeIDs.Load(t); ok {
+		return id.(string)
+	}
+	id, _ := typeIDs.LoadOrStore(t, "t"+strconv.FormatUint(lastID.Add(1), 10))
+
+	return id.(string)
+}
+
+// Hash returns the 64-bit FNV-1a hash of the key. Equal keys always produce the same hash.
+// The string and integer keys are hashed directly, without deriving their string representation.
+func Hash[K comparable](key K) uint64 {
+	switch k := any(key).(type) {
+	case string:
+		return hashString(k)
+	case int:
+		return hashUint(uint64(k))
+	case int8:
+		return hashUint(uint64(k))
+	case int16:
+		return hashUint(uint64(k))
+	case int32:
+		return hashUint(uint64(k))
+	case int64:
+		return hashUint(uint64(k))
+	case uint:
+		return hashUint(uint64(k))
+	case uint8:
+		return hashUint(uint64(k))
+	case uint16:
+		return hashUint(uint64(k))
+	case uint32:
+		return hashUint(uint64(k))
+	case uint64:
+		return hashUint(k)
+	case uintptr:
+		return hashUint(uint64(k))
+	}
+	return hashString(String(key))
+}
+
+// hashString returns the FNV-1a hash of the string.
+func hashString(s string) uint64 {
+	hash := uint64(fnvOffset64)
+	for i := 0; i < len(s); i++ {
+		hash ^= uint64(s[i])
+		hash *= fnvPrime64
+	}
+	return hash
+}
+
+// hashUint returns the FNV-1a hash of the little endian bytes of the integer.
+func hashUint(n uint64) uint64 {
+	hash := uint64(fnvOffset64)
+	for i := 0; i < 8; i++ {
+		hash ^= n & 0xff
+		hash *= fnvPrime64
+		n >>= 8
+	}
+	return hash
+}
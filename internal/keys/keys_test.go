@@ -0,0 +1,69 @@
+package keys
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys_String(t *testing.T) {
+	assert := assert.New(t)
+
+	type name string
+	type number int
+	type pair struct {
+		a, b any
+	}
+
+	assert.Equal("foo", String[name]("foo"))
+	assert.Equal("-1", String(-1))
+	assert.Equal("1", String[uint8](1))
+
+	// The values stored in interfaces are different keys if their dynamic types differ.
+	assert.NotEqual(String[any]("1"), String[any](1))
+	assert.NotEqual(String[any](1), String[any](int64(1)))
+	assert.NotEqual(String[any](1), String[any](number(1)))
+	assert.NotEqual(String(pair{1, 2}), String(pair{int64(1), 2}))
+	assert.NotEqual(String[any](nil), String[any]("nil"))
+	assert.Equal(String[any](number(1)), String[any](number(1)))
+	assert.Equal(String(pair{"a", 1}), String(pair{"a", 1}))
+
+	assert.NotEqual(
+		String(struct{ a, b string }{"a b", "c"}),
+		String(struct{ a, b string }{"a", "b c"}),
+	)
+	assert.NotEqual(
+		String(struct{ a, b string }{`a","b`, ""}),
+		String(struct{ a, b string }{"a", "b"}),
+	)
+	assert.NotEqual(String([2]any{1, nil}), String([2]any{nil, 1}))
+
+	// Equal keys produce the same string.
+	assert.Equal(String(0.0), String(math.Copysign(0, -1)))
+	assert.Equal(String[any](0.5), String[any](0.5))
+
+	x, y := 1, 1
+	assert.NotEqual(String(&x), String(&y))
+	assert.Equal(String(&x), String(&x))
+}
+
+func TestKeys_Hash(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(Hash("foo"), Hash("foo"))
+	assert.NotEqual(Hash("foo"), Hash("bar"))
+	assert.Equal(Hash(1000), Hash(1000))
+	assert.NotEqual(Hash(1000), Hash(1001))
+	assert.Equal(Hash[any](int64(7)), Hash[any](int64(7)))
+
+	type pair struct{ a, b int }
+	assert.Equal(Hash(pair{1, 2}), Hash(pair{1, 2}))
+	assert.NotEqual(Hash(pair{1, 2}), Hash(pair{2, 1}))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		Hash("foo")
+		Hash(123456789)
+	})
+	assert.Equal(0.0, allocs)
+}
@@ -1,47 +1,536 @@
 package gogu
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/esimov/gogu/cache"
+	"github.com/esimov/gogu/clock"
+	"github.com/esimov/gogu/internal/keys"
 	"golang.org/x/sync/singleflight"
 )
 
 // Memoizer is a two component struct type used to memoize the results of a function execution.
-// It holds an exported Cache storage and a singleflight group which is used
+// It holds a storage and a singleflight group which is used
 // to guarantee that only one function execution is in flight for a given key.
-type Memoizer[T ~string, V any] struct {
-	Cache *cache.Cache[T, V]
-	group *singleflight.Group
+// The Cache field is set only when the memoizer is created with NewMemoizer.
+type Memoizer[T comparable, V any] struct {
+	Cache  *cache.Cache[T, V]
+	store  cache.Store[T, V]
+	errs   *lazyCache[T, error]
+	group  *singleflight.Group
+	epochs *epochs[T]
 }
 
-// NewMemoizer instantiates a new Memoizer.
-func NewMemoizer[T ~string, V any](expiration, cleanup time.Duration) *Memoizer[T, V] {
+// memoizerConfig holds the optional settings of a memoizer.
+type memoizerConfig struct {
+	errTTL  time.Duration
+	ttl     time.Duration
+	lruSize int
+	clock   clock.Clock
+}
+
+// MemoizerOption customizes the memoizer behavior on initialization.
+type MemoizerOption func(*memoizerConfig)
+
+// WithErrorTTL enables the negative caching: the errors returned by the memoized functions are cached
+// for the provided duration, during which the function is not invoked again for the same key.
+// By default the errors are not cached. The option is applied by NewMemoizerWithStore and by the memoized
+// functions (Memoize1, Memoize2, MemoizeErr and MemoizeErr2). Since NewMemoizer accepts only cache options,
+// the negative caching of a TTL based memoizer is enabled by passing the cache to NewMemoizerWithStore:
+//
+//	m := NewMemoizerWithStore(cache.New[string, int](time.Minute, time.Minute).AsStore(), WithErrorTTL(time.Second))
+func WithErrorTTL(ttl time.Duration) MemoizerOption {
+	return func(cfg *memoizerConfig) {
+		cfg.errTTL = ttl
+	}
+}
+
+// WithTTL sets the expiration time of the values cached by the memoized functions
+// returned by Memoize1, Memoize2, MemoizeErr and MemoizeErr2. By default the values never expire.
+func WithTTL(ttl time.Duration) MemoizerOption {
+	return func(cfg *memoizerConfig) {
+		cfg.ttl = ttl
+	}
+}
+
+// WithLRU bounds the number of values cached by the memoized functions returned by Memoize1,
+// Memoize2, MemoizeErr and MemoizeErr2. The least recently used values are evicted once the limit
+// is reached. By default the number of the cached values is not limited.
+func WithLRU(size int) MemoizerOption {
+	return func(cfg *memoizerConfig) {
+		cfg.lruSize = size
+	}
+}
+
+// WithMemoizerClock sets the clock used for expiring the errors cached by the WithErrorTTL option
+// and the values cached by the memoized functions returned by Memoize1, Memoize2, MemoizeErr and MemoizeErr2.
+// The real clock is used by default. For NewMemoizer use the cache.WithClock option instead.
+func WithMemoizerClock(clk clock.Clock) MemoizerOption {
+	return func(cfg *memoizerConfig) {
+		cfg.clock = clk
+	}
+}
+
+func newMemoizerConfig(opts []MemoizerOption) memoizerConfig {
+	var cfg memoizerConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return cfg
+}
+
+// cacheOptions returns the options of the caches created by the memoizer.
+func (cfg memoizerConfig) cacheOptions() []cache.Option {
+	if cfg.clock == nil {
+		return nil
+	}
+	return []cache.Option{cache.WithClock(cfg.clock)}
+}
+
+// NewMemoizer instantiates a new Memoizer. The options are applied on the underlying cache storage.
+// Use NewMemoizerWithStore for customizing the memoizer itself with a MemoizerOption (e.g. WithErrorTTL).
+func NewMemoizer[T comparable, V any](expiration, cleanup time.Duration, opts ...cache.Option) *Memoizer[T, V] {
+	c := cache.New[T, V](expiration, cleanup, opts...)
+
 	return &Memoizer[T, V]{
-		Cache: cache.New[T, V](expiration, cleanup),
-		group: &singleflight.Group{},
+		Cache:  c,
+		store:  c.AsStore(),
+		group:  &singleflight.Group{},
+		epochs: newEpochs[T](),
+	}
+}
+
+// NewMemoizerWithStore instantiates a new Memoizer backed by the provided store (e.g. SyncLRU.AsStore).
+// Since the memoizer is meant to be used by concurrent callers, the store must be safe for concurrent use,
+// which is not the case for the adapters of LRUCache and of the other Bounded caches.
+// The WithTTL and WithLRU options are ignored, since they are used for creating the store.
+func NewMemoizerWithStore[T comparable, V any](store cache.Store[T, V], opts ...MemoizerOption) *Memoizer[T, V] {
+	cfg := newMemoizerConfig(opts)
+
+	m := &Memoizer[T, V]{
+		store:  store,
+		group:  &singleflight.Group{},
+		epochs: newEpochs[T](),
+	}
+	if cfg.errTTL > 0 {
+		m.errs = newLazyCache[T, error](cfg.errTTL, cfg)
 	}
+
+	return m
+}
+
+// AsStore returns the store backing the memoizer.
+func (m Memoizer[T, V]) AsStore() cache.Store[T, V] {
+	return m.store
 }
 
-// Memoize returns the item under a specific key instantly in case the key exists,
+// Memoize returns the value under a specific key instantly in case the key exists,
 // otherwise returns the results of the given function, making sure that only one execution
-// is in-flight for a given key at a time.
+// is in-flight for a given key at a time. The value is stored only if the function succeeds.
 //
 // This method is useful for caching the result of a time-consuming operation when is more important
 // to return a slightly outdated result, than to wait for an operation to complete before serving it.
-func (m Memoizer[T, V]) Memoize(key T, fn func() (*cache.Item[V], error)) (*cache.Item[V], error) {
-	item, _ := m.Cache.Get(key)
-	if item != nil {
-		return item, nil
-	}
+func (m Memoizer[T, V]) Memoize(key T, fn func() (V, error)) (V, error) {
+	val, _, err := m.MemoizeShared(key, fn)
+	return val, err
+}
+
+// MemoizeShared is like Memoize, but it also reports whether the result was served from the cache
+// or shared with other callers waiting for the same function execution.
+func (m Memoizer[T, V]) MemoizeShared(key T, fn func() (V, error)) (V, bool, error) {
+	return m.load(key, func() (V, time.Duration, error) {
+		val, err := fn()
+		return val, cache.DefaultExpiration, err
+	})
+}
 
-	data, err, _ := m.group.Do(string(key), func() (any, error) {
+// MemoizeItem is like Memoize, but the values are wrapped into cache items.
+// In case the function fails, the returned item is nil.
+//
+// Deprecated: MemoizeItem is the former Memoize method and it's kept for backward compatibility. Use Memoize instead.
+func (m Memoizer[T, V]) MemoizeItem(key T, fn func() (*cache.Item[V], error)) (*cache.Item[V], error) {
+	val, err := m.Memoize(key, func() (V, error) {
 		item, err := fn()
-		if err == nil {
-			m.Cache.SetDefault(key, item.Val())
-		}
-		return item, err
+		return item.Val(), err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cache.NewItem(val), nil
+}
+
+// MemoizeTTL is like Memoize, but the function returns the value together with the duration
+// for which the value should be cached. If the duration is 0 (or cache.DefaultExpiration) the
+// default expiration time of the store is used. The duration is ignored in case the store
+// does not support setting the expiration time of each value (see cache.ExpiringStore).
+func (m Memoizer[T, V]) MemoizeTTL(key T, fn func() (V, time.Duration, error)) (V, error) {
+	val, _, err := m.load(key, fn)
+	return val, err
+}
+
+// load returns the value stored under the key, otherwise the result of the function invocation,
+// making sure that only one execution is in-flight for a given key at a time. The errors are cached
+// if the negative caching is enabled. It reports whether the result was served from the cache
+// or shared with other callers.
+func (m Memoizer[T, V]) load(key T, fn func() (V, time.Duration, error)) (V, bool, error) {
+	var zero V
+
+	if val, ok := m.store.Get(key); ok {
+		return val, true, nil
+	}
+	if err, ok := m.cachedErr(key); ok {
+		return zero, true, err
+	}
+
+	data, err, shared := m.group.Do(keys.String(key), m.call(key, fn))
+	val, _ := data.(V)
+
+	return val, shared, err
+}
+
+// MemoizeCtx is like Memoize, but it returns promptly with the context error once the context is canceled,
+// while the function execution shared with the other callers continues in the background. For this reason
+// the function receives a context which carries the values of the provided context, but is never canceled.
+func (m Memoizer[T, V]) MemoizeCtx(ctx context.Context, key T, fn func(context.Context) (V, error)) (V, error) {
+	var zero V
+
+	if err := ctx.Err(); err != nil {
+		return zero, err
+	}
+	if val, ok := m.store.Get(key); ok {
+		return val, nil
+	}
+	if err, ok := m.cachedErr(key); ok {
+		return zero, err
+	}
+
+	detached := detachedContext{ctx}
+	ch := m.group.DoChan(keys.String(key), m.call(key, func() (V, time.Duration, error) {
+		val, err := fn(detached)
+		return val, cache.DefaultExpiration, err
+	}))
+
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		val, _ := res.Val.(V)
+		return val, res.Err
+	}
+}
+
+// call returns the function executed by the singleflight group. It stores the result of the function,
+// or caches its error if the negative caching is enabled, unless the key was forgotten in the meantime.
+func (m Memoizer[T, V]) call(key T, fn func() (V, time.Duration, error)) func() (any, error) {
+	return func() (any, error) {
+		epoch := m.epochs.begin(key)
+		val, ttl, err := fn()
+
+		// The result is stored without holding the lock of the epochs, since the store might invoke
+		// callbacks (e.g. an eviction callback) calling back into the memoizer.
+		stored := m.epochs.current(key, epoch)
+		if stored {
+			if err == nil {
+				m.set(key, val, ttl)
+			} else if m.errs != nil {
+				m.errs.Update(key, err, cache.DefaultExpiration)
+			}
+		}
+		if !m.epochs.end(key, epoch) && stored {
+			// The key was forgotten while the result was being stored, possibly after Forget removed it.
+			m.drop(key)
+		}
+
+		return val, err
+	}
+}
+
+// epochs tracks the epoch of the keys having in-flight function executions. Forget advances the epoch of a key,
+// and an execution stores its result only if the epoch of its key is the same as when the execution began.
+type epochs[T comparable] struct {
+	mu      sync.Mutex
+	entries map[T]*epoch
+}
+
+// epoch holds the current epoch of a key and the number of its in-flight function executions.
+type epoch struct {
+	n     uint64
+	calls int
+}
+
+func newEpochs[T comparable]() *epochs[T] {
+	return &epochs[T]{entries: make(map[T]*epoch)}
+}
+
+// begin registers a function execution of the key and returns the current epoch of the key.
+func (e *epochs[T]) begin(key T) uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	ep, ok := e.entries[key]
+	if !ok {
+		ep = &epoch{}
+		e.entries[key] = ep
+	}
+	ep.calls++
+
+	return ep.n
+}
+
+// current reports whether the epoch of the key is still the one in which the function execution began.
+func (e *epochs[T]) current(key T, n uint64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.entries[key].n == n
+}
+
+// end unregisters a function execution of the key which began in the provided epoch.
+// It reports whether the epoch of the key has not advanced in the meantime.
+func (e *epochs[T]) end(key T, n uint64) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	return data.(*cache.Item[V]), err
+	ep := e.entries[key]
+	if ep.calls--; ep.calls == 0 {
+		delete(e.entries, key)
+	}
+
+	return ep.n == n
+}
+
+// advance advances the epoch of the key, in case it has in-flight function executions.
+func (e *epochs[T]) advance(key T) {
+	e.mu.Lock()
+	if ep, ok := e.entries[key]; ok {
+		ep.n++
+	}
+	e.mu.Unlock()
+}
+
+// detachedContext carries the values of the parent context, but it's never canceled and has no deadline.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+func (c detachedContext) Value(key any) any         { return c.parent.Value(key) }
+
+// set stores the value with the provided expiration time, if the store supports it.
+func (m Memoizer[T, V]) set(key T, val V, ttl time.Duration) {
+	if s, ok := m.store.(cache.ExpiringStore[T, V]); ok {
+		s.SetWithTTL(key, val, ttl)
+		return
+	}
+	m.store.Set(key, val)
+}
+
+// cachedErr returns the cached error of the key, in case the negative caching is enabled.
+func (m Memoizer[T, V]) cachedErr(key T) (error, bool) {
+	if m.errs == nil {
+		return nil, false
+	}
+	item, err := m.errs.Get(key)
+	if err != nil {
+		return nil, false
+	}
+	return item.Val(), true
+}
+
+// Forget removes the value and the cached error of the key. It also drops the in-flight function execution
+// of the key, if any, so that the next call invokes the function again instead of waiting for its result.
+// The result of the dropped execution is still returned to its callers, but it's not stored anymore.
+func (m Memoizer[T, V]) Forget(key T) {
+	m.epochs.advance(key)
+	m.group.Forget(keys.String(key))
+	m.drop(key)
+}
+
+// drop removes the value and the cached error of the key.
+func (m Memoizer[T, V]) drop(key T) {
+	m.store.Delete(key)
+	if m.errs != nil {
+		m.errs.Delete(key)
+	}
+}
+
+// lazyCache is a TTL based cache used by the memoizer for caching the errors and the results of the
+// memoized functions. It has no cleanup goroutine, since the memoizer has no way of stopping it.
+// Instead, the expired items are removed while storing new ones, at most once per expiration period.
+type lazyCache[T comparable, V any] struct {
+	*cache.Cache[T, V]
+	ttl   time.Duration
+	clock clock.Clock
+
+	mu    sync.Mutex
+	swept time.Time
+}
+
+func newLazyCache[T comparable, V any](ttl time.Duration, cfg memoizerConfig) *lazyCache[T, V] {
+	clk := cfg.clock
+	if clk == nil {
+		clk = clock.New()
+	}
+
+	return &lazyCache[T, V]{
+		Cache: cache.New[T, V](ttl, 0, cfg.cacheOptions()...),
+		ttl:   ttl,
+		clock: clk,
+		swept: clk.Now(),
+	}
+}
+
+// Update stores the value and removes the expired items, if they were not removed during the last expiration period.
+func (c *lazyCache[T, V]) Update(key T, val V, d time.Duration) error {
+	err := c.Cache.Update(key, val, d)
+	if c.ttl <= 0 {
+		return err
+	}
+
+	c.mu.Lock()
+	now := c.clock.Now()
+	sweep := now.Sub(c.swept) >= c.ttl
+	if sweep {
+		c.swept = now
+	}
+	c.mu.Unlock()
+
+	if sweep {
+		c.DeleteExpired()
+	}
+	return err
+}
+
+// AsStore returns an adapter exposing the cache through the Store interface.
+func (c *lazyCache[T, V]) AsStore() cache.Store[T, V] {
+	return lazyStore[T, V]{c}
+}
+
+// lazyStore adapts the lazyCache to the Store and ExpiringStore interfaces.
+type lazyStore[T comparable, V any] struct {
+	c *lazyCache[T, V]
+}
+
+func (s lazyStore[T, V]) Get(key T) (V, bool) {
+	var v V
+
+	item, err := s.c.Get(key)
+	if err != nil {
+		return v, false
+	}
+	return item.Val(), true
+}
+
+func (s lazyStore[T, V]) Set(key T, val V) error {
+	return s.c.Update(key, val, cache.DefaultExpiration)
+}
+
+func (s lazyStore[T, V]) SetWithTTL(key T, val V, d time.Duration) error {
+	return s.c.Update(key, val, d)
+}
+
+func (s lazyStore[T, V]) Delete(key T) bool {
+	return s.c.Delete(key) == nil
+}
+
+func (s lazyStore[T, V]) Len() int {
+	return s.c.Count()
+}
+
+func (s lazyStore[T, V]) Clear() {
+	s.c.Flush()
+}
+
+// Stats returns a snapshot of the memoizer statistics.
+// The statistics are collected only if the memoizer was created with the cache.WithStats option,
+// or if the backing store reports them.
+func (m Memoizer[T, V]) Stats() cache.Stats {
+	if p, ok := m.store.(cache.StatsProvider); ok {
+		return p.Stats()
+	}
+	return cache.Stats{}
+}
+
+// ResetStats sets all the statistics counters back to zero.
+func (m Memoizer[T, V]) ResetStats() {
+	if p, ok := m.store.(cache.StatsProvider); ok {
+		p.ResetStats()
+	}
+}
+
+// newFuncMemoizer returns the memoizer backing a memoized function. The values are stored
+// in a concurrency-safe LRU cache if the WithLRU option is provided, otherwise in a TTL based cache.
+// Neither of them runs a cleanup goroutine, since the memoized function can't be closed.
+func newFuncMemoizer[T comparable, V any](opts []MemoizerOption) *Memoizer[T, V] {
+	cfg := newMemoizerConfig(opts)
+	if cfg.lruSize > 0 {
+		if lru, err := cache.NewSyncLRU[T, V](cfg.lruSize, cfg.ttl, cfg.cacheOptions()...); err == nil {
+			return NewMemoizerWithStore(lru.AsStore(), opts...)
+		}
+	}
+
+	return NewMemoizerWithStore(newLazyCache[T, V](cfg.ttl, cfg).AsStore(), opts...)
+}
+
+// Memoize1 returns a function which caches the results of fn by its argument.
+// The concurrent calls with the same argument share a single invocation of fn.
+// The cache is configured with the WithTTL and WithLRU options.
+func Memoize1[A comparable, R any](fn func(A) R, opts ...MemoizerOption) func(A) R {
+	m := newFuncMemoizer[A, R](opts)
+
+	return func(a A) R {
+		val, _ := m.Memoize(a, func() (R, error) {
+			return fn(a), nil
+		})
+		return val
+	}
+}
+
+// Memoize2 is like Memoize1, but it caches the results of a function with two arguments.
+func Memoize2[A, B comparable, R any](fn func(A, B) R, opts ...MemoizerOption) func(A, B) R {
+	type args struct {
+		a A
+		b B
+	}
+	m := newFuncMemoizer[args, R](opts)
+
+	return func(a A, b B) R {
+		val, _ := m.Memoize(args{a, b}, func() (R, error) {
+			return fn(a, b), nil
+		})
+		return val
+	}
+}
+
+// MemoizeErr is like Memoize1, but it caches the results of a function which can fail.
+// Only the successful results are cached, unless the WithErrorTTL option is provided.
+func MemoizeErr[A comparable, R any](fn func(A) (R, error), opts ...MemoizerOption) func(A) (R, error) {
+	m := newFuncMemoizer[A, R](opts)
+
+	return func(a A) (R, error) {
+		return m.Memoize(a, func() (R, error) {
+			return fn(a)
+		})
+	}
+}
+
+// MemoizeErr2 is like MemoizeErr, but it caches the results of a function with two arguments.
+func MemoizeErr2[A, B comparable, R any](fn func(A, B) (R, error), opts ...MemoizerOption) func(A, B) (R, error) {
+	type args struct {
+		a A
+		b B
+	}
+	m := newFuncMemoizer[args, R](opts)
+
+	return func(a A, b B) (R, error) {
+		return m.Memoize(args{a, b}, func() (R, error) {
+			return fn(a, b)
+		})
+	}
 }
@@ -1,11 +1,16 @@
 package gogu
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"strconv"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/esimov/gogu/cache"
+	"github.com/esimov/gogu/clock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,7 +26,7 @@ func TestMemoize(t *testing.T) {
 	}
 
 	// Here we are simulating an expensive operation.
-	expensiveOp := func() (*cache.Item[any], error) {
+	expensiveOp := func() (any, error) {
 		// Here we are simulating an expensive operation.
 		time.Sleep(500 * time.Millisecond)
 
@@ -34,7 +39,7 @@ func TestMemoize(t *testing.T) {
 		if err != nil {
 			return nil, err
 		}
-		return item, nil
+		return item.Val(), nil
 	}
 	assert.Empty(m.Cache.List())
 	// Caching the result of some expensive fictive operation result.
@@ -66,7 +71,7 @@ func Example_memoize() {
 	}
 
 	// Here we are simulating an expensive operation.
-	expensiveOp := func() (*cache.Item[any], error) {
+	expensiveOp := func() (any, error) {
 		// Here we are simulating an expensive operation.
 		time.Sleep(500 * time.Millisecond)
 
@@ -79,7 +84,7 @@ func Example_memoize() {
 		if err != nil {
 			return nil, err
 		}
-		return item, nil
+		return item.Val(), nil
 	}
 	// Cache is empty this time.
 	fmt.Println(m.Cache.List())
@@ -94,7 +99,7 @@ func Example_memoize() {
 	// Serving the expensive operation result from the cache. This should return instantly.
 	// If it would invoked the expensiveOp function this would be introduced a 500 millisecond latency.
 	data, _ := m.Memoize("key1", expensiveOp)
-	fmt.Println(data.Val())
+	fmt.Println(data)
 
 	// Output:
 	// map[]
@@ -102,3 +107,502 @@ func Example_memoize() {
 	// one
 	// one
 }
+
+func TestMemoize_Stats(t *testing.T) {
+	assert := assert.New(t)
+
+	var _ cache.StatsProvider = Memoizer[string, int]{}
+
+	m := NewMemoizer[string, int](time.Minute, 0, cache.WithStats())
+	fn := func() (int, error) {
+		m.Cache.Set("item", 1, cache.DefaultExpiration)
+		item, err := m.Cache.Get("item")
+		return item.Val(), err
+	}
+
+	m.Memoize("key", fn)
+	m.Memoize("key", fn)
+	m.Memoize("key", fn)
+
+	stats := m.Stats()
+	assert.Equal(uint64(1), stats.Misses)
+	assert.Equal(uint64(3), stats.Hits)
+	assert.Equal(uint64(2), stats.Sets)
+
+	m.ResetStats()
+	assert.Equal(cache.Stats{}, m.Stats())
+}
+
+func TestMemoize_ComparableKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	type key struct{ id int }
+
+	m := NewMemoizer[key, int](time.Minute, 0)
+	val, err := m.Memoize(key{1}, func() (int, error) {
+		m.Cache.Set(key{100}, 100, cache.DefaultExpiration)
+		item, err := m.Cache.Get(key{100})
+		return item.Val(), err
+	})
+	assert.NoError(err)
+	assert.Equal(100, val)
+
+	item, err := m.Cache.Get(key{1})
+	assert.NoError(err)
+	assert.Equal(100, item.Val())
+}
+
+func TestMemoize_InterfaceKeys(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMemoizer[any, string](time.Minute, 0)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan string)
+	go func() {
+		val, err := m.Memoize(1, func() (string, error) {
+			close(started)
+			<-release
+			return "int", nil
+		})
+		assert.NoError(err)
+		done <- val
+	}()
+	<-started
+
+	// The key int64(1) differs from int(1), so it doesn't share the result of the call in flight.
+	val, err := m.Memoize(int64(1), func() (string, error) {
+		return "int64", nil
+	})
+	assert.NoError(err)
+	assert.Equal("int64", val)
+
+	close(release)
+	assert.Equal("int", <-done)
+}
+
+func TestMemoize_WithStore(t *testing.T) {
+	assert := assert.New(t)
+
+	lru, err := cache.NewSyncLRU[string, int](2, cache.NoExpiration)
+	assert.NoError(err)
+
+	var calls int
+	m := NewMemoizerWithStore(lru.AsStore())
+	fn := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	val, err := m.Memoize("key", fn)
+	assert.NoError(err)
+	assert.Equal(1, val)
+
+	val, err = m.Memoize("key", fn)
+	assert.NoError(err)
+	assert.Equal(1, val)
+	assert.Equal(1, calls)
+	assert.Equal(1, m.AsStore().Len())
+	assert.Nil(m.Cache)
+
+	// The store is safe for concurrent use.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			val, err := m.Memoize(strconv.Itoa(i%4), func() (int, error) {
+				return i, nil
+			})
+			assert.NoError(err)
+			assert.GreaterOrEqual(val, 0)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(2, m.AsStore().Len())
+}
+
+func TestMemoize_ErrorTTL(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int
+	errBackend := fmt.Errorf("backend unavailable")
+	fn := func() (int, error) {
+		calls++
+		return 0, errBackend
+	}
+
+	// The errors are not cached by default.
+	m := NewMemoizer[string, int](time.Minute, 0)
+	_, err := m.Memoize("key", fn)
+	assert.ErrorIs(err, errBackend)
+	_, err = m.Memoize("key", fn)
+	assert.ErrorIs(err, errBackend)
+	assert.Equal(2, calls)
+
+	calls = 0
+	m = NewMemoizerWithStore(cache.New[string, int](time.Minute, 0).AsStore(), WithErrorTTL(50*time.Millisecond))
+	val, err := m.Memoize("key", fn)
+	assert.ErrorIs(err, errBackend)
+	assert.Zero(val)
+	_, err = m.Memoize("key", fn)
+	assert.ErrorIs(err, errBackend)
+	assert.Equal(1, calls)
+
+	// The function is invoked again once the cached error expires.
+	time.Sleep(60 * time.Millisecond)
+	_, err = m.Memoize("key", fn)
+	assert.ErrorIs(err, errBackend)
+	assert.Equal(2, calls)
+
+	// Forget drops the cached error.
+	m.Forget("key")
+	_, err = m.Memoize("key", fn)
+	assert.ErrorIs(err, errBackend)
+	assert.Equal(3, calls)
+}
+
+func TestMemoize_ErrorCache(t *testing.T) {
+	assert := assert.New(t)
+
+	clk := clock.NewFake(time.Unix(0, 0))
+	m := NewMemoizerWithStore(cache.New[string, int](time.Minute, 0).AsStore(),
+		WithErrorTTL(time.Second), WithMemoizerClock(clk))
+
+	fail := func() (int, error) { return 0, fmt.Errorf("failed") }
+	m.Memoize("a", fail)
+	m.Memoize("b", fail)
+	assert.Equal(2, m.errs.Count())
+
+	// The expired errors are removed while caching a new error, once the expiration period has passed.
+	clk.Advance(2 * time.Second)
+	m.Memoize("c", fail)
+	assert.Equal(1, m.errs.Count())
+	_, ok := m.cachedErr("c")
+	assert.True(ok)
+}
+
+func TestMemoize_EvictionCallback(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMemoizer[string, int](time.Minute, 0, cache.WithMaxEntries(1))
+	var evicted []string
+	// The eviction callback triggered while storing a result can call back into the memoizer.
+	m.Cache.OnEvicted(func(key string, _ int, reason cache.EvictReason) {
+		evicted = append(evicted, key)
+		m.Forget(key)
+	})
+
+	m.Memoize("a", func() (int, error) { return 1, nil })
+	val, err := m.Memoize("b", func() (int, error) { return 2, nil })
+	assert.NoError(err)
+	assert.Equal(2, val)
+	assert.Equal([]string{"a"}, evicted)
+	assert.Empty(m.epochs.entries)
+}
+
+func TestMemoize_TTL(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int
+	fn := func() (int, time.Duration, error) {
+		calls++
+		return calls, 20 * time.Millisecond, nil
+	}
+
+	m := NewMemoizer[string, int](time.Minute, 0)
+	val, err := m.MemoizeTTL("key", fn)
+	assert.NoError(err)
+	assert.Equal(1, val)
+	val, _ = m.MemoizeTTL("key", fn)
+	assert.Equal(1, val)
+
+	// The value expires after the duration returned by the function, instead of the default expiration time.
+	time.Sleep(30 * time.Millisecond)
+	val, _ = m.MemoizeTTL("key", fn)
+	assert.Equal(2, val)
+
+	// The duration is ignored by the stores which don't support it.
+	lru, _ := cache.NewSyncLRU[string, int](10, cache.NoExpiration)
+	m = NewMemoizerWithStore[string, int](struct{ cache.Store[string, int] }{lru.AsStore()})
+	m.MemoizeTTL("key", fn)
+	time.Sleep(30 * time.Millisecond)
+	val, _ = m.MemoizeTTL("key", fn)
+	assert.Equal(3, val)
+}
+
+func TestMemoize_Forget(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMemoizer[string, int](time.Minute, 0)
+	started, release, done := make(chan struct{}), make(chan struct{}), make(chan struct{})
+
+	go func() {
+		defer close(done)
+		m.MemoizeTTL("key", func() (int, time.Duration, error) {
+			close(started)
+			<-release
+			return 1, cache.DefaultExpiration, nil
+		})
+	}()
+	<-started
+
+	// After forgetting the key, the in-flight execution is not waited for anymore.
+	m.Forget("key")
+	val, err := m.MemoizeTTL("key", func() (int, time.Duration, error) {
+		return 2, cache.DefaultExpiration, nil
+	})
+	assert.NoError(err)
+	assert.Equal(2, val)
+
+	// The forgotten execution does not overwrite the value stored in the meantime with its stale result.
+	close(release)
+	<-done
+	val, ok := m.AsStore().Get("key")
+	assert.True(ok)
+	assert.Equal(2, val)
+
+	m.Forget("key")
+	_, ok = m.AsStore().Get("key")
+	assert.False(ok)
+
+	// Nor does it store its stale result once the key is forgotten while the execution is in flight.
+	started, release, done = make(chan struct{}), make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		val, err := m.Memoize("key", func() (int, error) {
+			close(started)
+			<-release
+			return 3, nil
+		})
+		assert.NoError(err)
+		assert.Equal(3, val)
+	}()
+	<-started
+	m.Forget("key")
+	close(release)
+	<-done
+
+	_, ok = m.AsStore().Get("key")
+	assert.False(ok)
+	assert.Empty(m.epochs.entries)
+}
+
+func TestMemoize_Ctx(t *testing.T) {
+	assert := assert.New(t)
+
+	type ctxKey struct{}
+
+	m := NewMemoizer[string, int](time.Minute, 0)
+	started, release := make(chan struct{}), make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		// The context of the shared execution carries the values, but it's never canceled.
+		assert.NoError(ctx.Err())
+		return ctx.Value(ctxKey{}).(int), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, 1))
+	waiter := make(chan int)
+	go func() {
+		<-started
+		val, err := m.MemoizeCtx(context.Background(), "key", fn)
+		assert.NoError(err)
+		waiter <- val
+	}()
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	// The canceled caller returns promptly, while the other waiter gets the result of the shared execution.
+	_, err := m.MemoizeCtx(ctx, "key", fn)
+	assert.ErrorIs(err, context.Canceled)
+	close(release)
+	assert.Equal(1, <-waiter)
+
+	val, err := m.MemoizeCtx(context.Background(), "key", fn)
+	assert.NoError(err)
+	assert.Equal(1, val)
+
+	// The function is not invoked with an already canceled context.
+	_, err = m.MemoizeCtx(ctx, "other", fn)
+	assert.ErrorIs(err, context.Canceled)
+}
+
+func TestMemoize_Shared(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMemoizer[string, int](time.Minute, 0)
+	fn := func() (int, error) {
+		return 1, nil
+	}
+
+	val, shared, err := m.MemoizeShared("key", fn)
+	assert.NoError(err)
+	assert.Equal(1, val)
+	assert.False(shared)
+
+	val, shared, err = m.MemoizeShared("key", fn)
+	assert.NoError(err)
+	assert.Equal(1, val)
+	assert.True(shared)
+}
+
+func TestMemoize_Item(t *testing.T) {
+	assert := assert.New(t)
+
+	m := NewMemoizer[string, int](time.Minute, 0)
+
+	// The failing function returning a nil item doesn't panic.
+	item, err := m.MemoizeItem("key", func() (*cache.Item[int], error) {
+		return nil, fmt.Errorf("failed")
+	})
+	assert.Error(err)
+	assert.Nil(item)
+
+	item, err = m.MemoizeItem("key", func() (*cache.Item[int], error) {
+		return cache.NewItem(1), nil
+	})
+	assert.NoError(err)
+	assert.Equal(1, item.Val())
+
+	val, err := m.Memoize("key", func() (int, error) {
+		return 2, nil
+	})
+	assert.NoError(err)
+	assert.Equal(1, val)
+}
+
+func TestMemoize_Func(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int
+	square := Memoize1(func(n int) int {
+		calls++
+		return n * n
+	})
+	assert.Equal(4, square(2))
+	assert.Equal(4, square(2))
+	assert.Equal(9, square(3))
+	assert.Equal(2, calls)
+
+	calls = 0
+	add := Memoize2(func(a, b int) int {
+		calls++
+		return a + b
+	})
+	assert.Equal(3, add(1, 2))
+	assert.Equal(3, add(1, 2))
+	assert.Equal(3, add(2, 1))
+	assert.Equal(2, calls)
+
+	calls = 0
+	errNegative := fmt.Errorf("negative number")
+	abs := MemoizeErr(func(n int) (int, error) {
+		calls++
+		if n < 0 {
+			return 0, errNegative
+		}
+		return Abs(n), nil
+	}, WithErrorTTL(time.Minute))
+	_, err := abs(-1)
+	assert.ErrorIs(err, errNegative)
+	_, err = abs(-1)
+	assert.ErrorIs(err, errNegative)
+	val, err := abs(4)
+	assert.NoError(err)
+	assert.Equal(4, val)
+	assert.Equal(2, calls)
+
+	calls = 0
+	div := MemoizeErr2(func(a, b int) (int, error) {
+		calls++
+		if b == 0 {
+			return 0, errNegative
+		}
+		return a / b, nil
+	})
+	_, err = div(1, 0)
+	assert.Error(err)
+	_, err = div(1, 0)
+	assert.Error(err)
+	val, err = div(4, 2)
+	assert.NoError(err)
+	assert.Equal(2, val)
+	assert.Equal(3, calls)
+}
+
+func TestMemoize_FuncOptions(t *testing.T) {
+	assert := assert.New(t)
+
+	var calls int
+	fn := func(n int) int {
+		calls++
+		return n
+	}
+
+	// The least recently used results are evicted.
+	lru := Memoize1(fn, WithLRU(2))
+	lru(1)
+	lru(2)
+	lru(3)
+	lru(3)
+	assert.Equal(3, calls)
+	lru(1)
+	assert.Equal(4, calls)
+
+	// The results expire after the provided duration.
+	clk := clock.NewFake(time.Now())
+	calls = 0
+	ttl := Memoize1(fn, WithTTL(time.Minute), WithMemoizerClock(clk))
+	ttl(1)
+	ttl(1)
+	assert.Equal(1, calls)
+	clk.Advance(2 * time.Minute)
+	ttl(1)
+	assert.Equal(2, calls)
+
+	calls = 0
+	both := Memoize1(fn, WithLRU(1), WithTTL(time.Minute), WithMemoizerClock(clk))
+	both(1)
+	both(1)
+	clk.Advance(2 * time.Minute)
+	both(1)
+	both(2)
+	both(1)
+	assert.Equal(4, calls)
+
+	// The cached errors expire after the provided duration too.
+	calls = 0
+	failing := MemoizeErr(func(n int) (int, error) {
+		calls++
+		return 0, fmt.Errorf("failed %d", n)
+	}, WithErrorTTL(time.Minute), WithMemoizerClock(clk))
+	_, err := failing(1)
+	assert.Error(err)
+	_, err = failing(1)
+	assert.Error(err)
+	assert.Equal(1, calls)
+	clk.Advance(2 * time.Minute)
+	_, err = failing(1)
+	assert.Error(err)
+	assert.Equal(2, calls)
+
+	// The memoized functions don't start cleanup goroutines, which couldn't be stopped.
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		Memoize1(fn, WithTTL(time.Minute), WithErrorTTL(time.Minute))
+	}
+	assert.LessOrEqual(runtime.NumGoroutine(), before)
+
+	// Instead, the expired results are removed while storing new ones.
+	m := newFuncMemoizer[int, int]([]MemoizerOption{WithTTL(time.Minute), WithMemoizerClock(clk)})
+	m.Memoize(1, func() (int, error) { return 1, nil })
+	m.Memoize(2, func() (int, error) { return 2, nil })
+	clk.Advance(2 * time.Minute)
+	m.Memoize(3, func() (int, error) { return 3, nil })
+	assert.Equal(1, m.AsStore().Len())
+}